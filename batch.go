@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"sentimentbayes/sentiment"
+)
+
+// minParallelBatch is the batch size below which /batch classifies
+// sequentially, since spinning up workers costs more than it saves.
+const minParallelBatch = 32
+
+// batchRequest lists the texts to classify either as plain texts, which use
+// the server's settings, or as items that may override them individually.
+type batchRequest struct {
+	Texts []string    `json:"texts,omitempty"`
+	Items []batchItem `json:"items,omitempty"`
+}
+
+type batchItem struct {
+	Text    string            `json:"text"`
+	Options *batchItemOptions `json:"options,omitempty"`
+}
+
+// batchItemOptions overrides the -neutral-band, -min-known-fraction and
+// -abstain-label settings for one item. Omitted fields keep the flag values.
+type batchItemOptions struct {
+	NeutralBand      *float64 `json:"neutral_band,omitempty"`
+	MinKnownFraction *float64 `json:"min_known_fraction,omitempty"`
+	AbstainLabel     *string  `json:"abstain_label,omitempty"`
+}
+
+// policy returns the default policy with the item's overrides applied.
+func (item batchItem) policy() (predictPolicy, error) {
+	policy := defaultPolicy()
+	opts := item.Options
+	if opts == nil {
+		return policy, nil
+	}
+	if opts.NeutralBand != nil {
+		if *opts.NeutralBand < 0 || *opts.NeutralBand > 0.5 {
+			return policy, fmt.Errorf("neutral_band must be in [0,0.5], got %g", *opts.NeutralBand)
+		}
+		policy.NeutralBand = *opts.NeutralBand
+	}
+	if opts.MinKnownFraction != nil {
+		if *opts.MinKnownFraction < 0 || *opts.MinKnownFraction > 1 {
+			return policy, fmt.Errorf("min_known_fraction must be in [0,1], got %g", *opts.MinKnownFraction)
+		}
+		policy.MinKnownFraction = *opts.MinKnownFraction
+	}
+	if opts.AbstainLabel != nil {
+		policy.AbstainLabel = *opts.AbstainLabel
+	}
+	return policy, nil
+}
+
+type batchResponse struct {
+	Results   []classifyResponse `json:"results"`
+	RequestID string             `json:"request_id,omitempty"`
+}
+
+// batchHandler classifies many texts in one request using up to workers
+// goroutines. Results keep the order of the request texts or items. The read
+// and write deadlines are extended to timeout before the body is decoded, so
+// large batches are not cut off by the server-wide read and write timeouts.
+func batchHandler(classifier *sentiment.NaiveBayesClassifier, workers int, timeout time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if timeout > 0 {
+			// Not every ResponseWriter supports deadlines; the server-wide timeouts apply then.
+			rc := http.NewResponseController(w)
+			deadline := time.Now().Add(timeout)
+			_ = rc.SetReadDeadline(deadline)
+			_ = rc.SetWriteDeadline(deadline)
+		}
+		var req batchRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if len(req.Texts) > 0 && len(req.Items) > 0 {
+			http.Error(w, "texts and items are mutually exclusive", http.StatusBadRequest)
+			return
+		}
+		items := req.Items
+		for _, text := range req.Texts {
+			items = append(items, batchItem{Text: text})
+		}
+		if len(items) == 0 {
+			http.Error(w, "texts or items is required", http.StatusBadRequest)
+			return
+		}
+		policies := make([]predictPolicy, len(items))
+		for i, item := range items {
+			policy, err := item.policy()
+			if err != nil {
+				http.Error(w, fmt.Sprintf("items[%d]: %v", i, err), http.StatusBadRequest)
+				return
+			}
+			policies[i] = policy
+		}
+
+		results := classifyAll(classifier, items, policies, workers)
+		resp := batchResponse{Results: results, RequestID: requestIDFrom(r.Context())}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// classifyAll predicts every item under its policy, fanning out to a bounded
+// worker pool for large inputs. Predict only reads model state, so concurrent
+// calls are safe while no training happens.
+func classifyAll(classifier *sentiment.NaiveBayesClassifier, items []batchItem, policies []predictPolicy, workers int) []classifyResponse {
+	results := make([]classifyResponse, len(items))
+	if workers <= 1 || len(items) < minParallelBatch {
+		for i, item := range items {
+			label, probs := predictTextPolicy(classifier, item.Text, policies[i])
+			results[i] = classifyResponse{Label: label, Probabilities: roundProbabilities(probs)}
+		}
+		return results
+	}
+	if workers > len(items) {
+		workers = len(items)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				label, probs := predictTextPolicy(classifier, items[idx].Text, policies[idx])
+				results[idx] = classifyResponse{Label: label, Probabilities: roundProbabilities(probs)}
+			}
+		}()
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
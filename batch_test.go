@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"sentimentbayes/sentiment"
+)
+
+// trainedClassifier returns a classifier trained on the built-in dataset.
+func trainedClassifier() *sentiment.NaiveBayesClassifier {
+	classifier := sentiment.NewNaiveBayesClassifier()
+	classifier.TrainBatch(sentiment.DefaultDataset())
+	return classifier
+}
+
+// batchItems returns n distinct texts with the default policy.
+func batchItems(n int) ([]batchItem, []predictPolicy) {
+	items := make([]batchItem, n)
+	policies := make([]predictPolicy, n)
+	for i := range items {
+		items[i] = batchItem{Text: fmt.Sprintf("the service was great but the food %d was cold and slow", i)}
+		policies[i] = defaultPolicy()
+	}
+	return items, policies
+}
+
+func TestClassifyAllMatchesSequential(t *testing.T) {
+	classifier := trainedClassifier()
+	items, policies := batchItems(100)
+	sequential := classifyAll(classifier, items, policies, 1)
+	pooled := classifyAll(classifier, items, policies, 8)
+	for i := range items {
+		if sequential[i].Label != pooled[i].Label || fmt.Sprint(sequential[i].Probabilities) != fmt.Sprint(pooled[i].Probabilities) {
+			t.Fatalf("item %d: pooled %+v differs from sequential %+v", i, pooled[i], sequential[i])
+		}
+	}
+}
+
+func TestBatchHandlerPerItemOptions(t *testing.T) {
+	classifier := sentiment.NewNaiveBayesClassifier()
+	classifier.Train("good good great", "positive")
+	classifier.Train("bad good", "negative")
+	handler := batchHandler(classifier, 1, 0)
+
+	// P(positive|great) = 5/8; "zebra" is out of vocabulary.
+	band, wide := 0.1, 0.2
+	known := 0.75
+	unsure := "unsure"
+	req := batchRequest{Items: []batchItem{
+		{Text: "great"},
+		{Text: "great", Options: &batchItemOptions{NeutralBand: &band}},
+		{Text: "great", Options: &batchItemOptions{NeutralBand: &wide}},
+		{Text: "great zebra", Options: &batchItemOptions{MinKnownFraction: &known}},
+		{Text: "great zebra", Options: &batchItemOptions{MinKnownFraction: &known, AbstainLabel: &unsure}},
+		{Text: "great zebra"},
+	}}
+	var resp batchResponse
+	decodeBody(t, serve(t, handler, http.MethodPost, "/batch", req), &resp)
+	want := []string{"positive", "positive", "neutral", "unknown", "unsure", "positive"}
+	if len(resp.Results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(resp.Results), len(want))
+	}
+	for i, result := range resp.Results {
+		if result.Label != want[i] {
+			t.Errorf("item %d (%q) = %s, want %s", i, req.Items[i].Text, result.Label, want[i])
+		}
+	}
+
+	invalid := 0.7
+	bad := batchRequest{Items: []batchItem{{Text: "great"}, {Text: "great", Options: &batchItemOptions{NeutralBand: &invalid}}}}
+	if rec := serve(t, handler, http.MethodPost, "/batch", bad); rec.Code != http.StatusBadRequest {
+		t.Errorf("neutral_band 0.7: status = %d, want 400", rec.Code)
+	}
+	mixed := batchRequest{Texts: []string{"great"}, Items: []batchItem{{Text: "bad"}}}
+	if rec := serve(t, handler, http.MethodPost, "/batch", mixed); rec.Code != http.StatusBadRequest {
+		t.Errorf("texts and items together: status = %d, want 400", rec.Code)
+	}
+}
+
+// postSlowly posts body to url, pausing for pause halfway through the body.
+func postSlowly(url, body string, pause time.Duration) (*http.Response, error) {
+	r, w := io.Pipe()
+	go func() {
+		half := len(body) / 2
+		io.WriteString(w, body[:half])
+		time.Sleep(pause)
+		io.WriteString(w, body[half:])
+		w.Close()
+	}()
+	return http.Post(url, "application/json", r)
+}
+
+func TestBatchExtendsReadDeadline(t *testing.T) {
+	srv := httptest.NewUnstartedServer(buildRouter(trainedClassifier(), nil, nil))
+	srv.Config.ReadTimeout = 100 * time.Millisecond
+	srv.Start()
+	defer srv.Close()
+
+	// The server-wide read timeout cuts off a slow /classify body...
+	resp, err := postSlowly(srv.URL+"/classify", `{"text": "great phone"}`, 300*time.Millisecond)
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			t.Error("a /classify body slower than -read-timeout was accepted")
+		}
+	}
+
+	// ...while /batch reads under -batch-timeout.
+	resp, err = postSlowly(srv.URL+"/batch", `{"texts": ["great phone", "awful screen"]}`, 300*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Errorf("/batch status = %d %q, want 200", resp.StatusCode, body)
+	}
+}
+
+func BenchmarkBatch(b *testing.B) {
+	classifier := trainedClassifier()
+	items, policies := batchItems(1000)
+	for _, workers := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				classifyAll(classifier, items, policies, workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"sentimentbayes/sentiment"
+)
+
+// runCompareMode evaluates the loaded snapshot (model A) and the snapshot at
+// comparePath (model B) on the same test documents, chosen as in evaluate mode
+// (honoring -stratified and -test-dataset), and lists the test documents on
+// which their predictions differ.
+func runCompareMode(modelA *sentiment.NaiveBayesClassifier, docs []sentiment.Document, comparePath string, split float64, seed int64) error {
+	if *loadSnapshotPath == "" || comparePath == "" {
+		return errors.New("compare mode requires -load-snapshot and -compare-snapshot")
+	}
+	logModelSummary(modelA)
+	modelB := sentiment.NewNaiveBayesClassifier()
+	if _, err := loadSnapshotFromDisk(modelB, comparePath, nil); err != nil {
+		return err
+	}
+
+	_, test, err := evaluationSplit(docs, split, seed)
+	if err != nil {
+		return err
+	}
+	metricsA := sentiment.Evaluate(modelA, test)
+	metricsB := sentiment.Evaluate(modelB, test)
+
+	fmt.Printf("Test set size: %d\n", len(test))
+	fmt.Printf("Model A (%s) accuracy: %.2f%% (%d/%d)\n", *loadSnapshotPath, metricsA.Accuracy()*100, metricsA.Correct, metricsA.Total)
+	fmt.Printf("Model B (%s) accuracy: %.2f%% (%d/%d)\n", comparePath, metricsB.Accuracy()*100, metricsB.Correct, metricsB.Total)
+
+	disagreements := 0
+	for _, doc := range test {
+		labelA, _ := modelA.Predict(doc.Text)
+		labelB, _ := modelB.Predict(doc.Text)
+		if labelA == labelB {
+			continue
+		}
+		if disagreements == 0 {
+			fmt.Println("Disagreements (actual: A / B):")
+		}
+		disagreements++
+		fmt.Printf("  %q (%s): %s / %s\n", doc.Text, doc.Label, labelA, labelB)
+	}
+	fmt.Printf("Models disagree on %d of %d test documents\n", disagreements, len(test))
+	return nil
+}
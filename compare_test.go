@@ -0,0 +1,130 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sentimentbayes/sentiment"
+)
+
+// setFlag sets a flag variable for the duration of a test.
+func setFlag[T any](t *testing.T, p *T, v T) {
+	t.Helper()
+	old := *p
+	*p = v
+	t.Cleanup(func() { *p = old })
+}
+
+// captureStdout returns what fn prints to standard output.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stdout
+	os.Stdout = w
+	done := make(chan []byte)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- out
+	}()
+	defer func() { os.Stdout = old }()
+	fn()
+	w.Close()
+	return string(<-done)
+}
+
+func TestEvaluationSplitTestDataset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.csv")
+	if err := os.WriteFile(path, []byte("text,label\nheld out good,positive\nheld out bad,negative\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	setFlag(t, testDataset, path)
+
+	docs := sentiment.DefaultDataset()
+	train, test, err := evaluationSplit(docs, 0.8, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(train) != len(docs) || len(test) != 2 || test[0].Text != "held out good" {
+		t.Errorf("got %d train / %v test, want all %d docs and the held-out file", len(train), test, len(docs))
+	}
+}
+
+func TestEvaluationSplitStratified(t *testing.T) {
+	setFlag(t, stratified, true)
+	docs := []sentiment.Document{
+		{Text: "a", Label: "positive"}, {Text: "b", Label: "positive"},
+		{Text: "c", Label: "positive"}, {Text: "d", Label: "positive"},
+		{Text: "e", Label: "negative"}, {Text: "f", Label: "negative"},
+	}
+	for seed := int64(0); seed < 20; seed++ {
+		_, test, err := evaluationSplit(docs, 0.5, seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		labels := distinctLabels(test)
+		if len(labels) != 2 {
+			t.Fatalf("seed %d: test labels %v, want both classes", seed, labels)
+		}
+	}
+}
+
+func TestRunEvaluationModeSeparateTestFile(t *testing.T) {
+	dir := t.TempDir()
+	trainPath := filepath.Join(dir, "train.csv")
+	testPath := filepath.Join(dir, "test.csv")
+	trainCSV := "text,label\nlovely food,positive\ngreat staff,positive\nlovely view,positive\nawful food,negative\nrude staff,negative\n"
+	testCSV := "text,label\nlovely staff,positive\nawful and rude,negative\nrude waiter,negative\n"
+	if err := os.WriteFile(trainPath, []byte(trainCSV), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(testPath, []byte(testCSV), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	docs, err := readDataset(trainPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setFlag(t, testDataset, testPath)
+
+	classifier := sentiment.NewNaiveBayesClassifier()
+	var runErr error
+	out := captureStdout(t, func() {
+		runErr = runEvaluationMode(classifier, docs, 0.5, 1, 0)
+	})
+	if runErr != nil {
+		t.Fatal(runErr)
+	}
+	for _, want := range []string{"Train set size: 5\n", "Test set size: 3\n", "Accuracy: 100.00% (3/3)\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+	if classifier.Snapshot().TotalDocs != 5 {
+		t.Errorf("trained on %v documents, want the whole training file", classifier.Snapshot().TotalDocs)
+	}
+}
+
+func TestRunEvaluationModeSingleLabel(t *testing.T) {
+	docs := []sentiment.Document{
+		{Text: "great food", Label: "positive"},
+		{Text: "lovely staff", Label: "positive"},
+		{Text: "nice view", Label: "positive"},
+	}
+	classifier := sentiment.NewNaiveBayesClassifier()
+	var err error
+	out := captureStdout(t, func() {
+		err = runEvaluationMode(classifier, docs, 0.5, 1, 0)
+	})
+	if err == nil || !strings.Contains(err.Error(), `single label "positive"`) {
+		t.Errorf("err = %v, want a single-label error", err)
+	}
+	if out != "" {
+		t.Errorf("printed %q before failing, want no report", out)
+	}
+}
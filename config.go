@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// Config mirrors the most commonly shared command-line flags so a run can be
+// described in a JSON file passed with -config. Fields left out of the file
+// keep their flag defaults, and flags given explicitly on the command line
+// override the file: defaults < file < flags. Durations use Go syntax ("30s").
+type Config struct {
+	Mode    *string  `json:"mode"`
+	Dataset *string  `json:"dataset"`
+	Split   *float64 `json:"split"`
+	Seed    *int64   `json:"seed"`
+
+	Smoothing *string  `json:"smoothing"`
+	Alpha     *float64 `json:"alpha"`
+
+	Port         *int    `json:"port"`
+	ReadTimeout  *string `json:"read_timeout"`
+	WriteTimeout *string `json:"write_timeout"`
+	IdleTimeout  *string `json:"idle_timeout"`
+	BatchWorkers *int    `json:"batch_workers"`
+	TLSCert      *string `json:"tls_cert"`
+	TLSKey       *string `json:"tls_key"`
+}
+
+// loadConfigFile decodes a Config from path, rejecting unknown fields so
+// typos do not go unnoticed.
+func loadConfigFile(path string) (Config, error) {
+	var cfg Config
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, fmt.Errorf("load config: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("decode config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// values returns the flag values set in the file, keyed by flag name.
+func (c Config) values() map[string]string {
+	values := make(map[string]string)
+	setString := func(name string, v *string) {
+		if v != nil {
+			values[name] = *v
+		}
+	}
+	setFloat := func(name string, v *float64) {
+		if v != nil {
+			values[name] = strconv.FormatFloat(*v, 'g', -1, 64)
+		}
+	}
+	setInt := func(name string, v *int) {
+		if v != nil {
+			values[name] = strconv.Itoa(*v)
+		}
+	}
+
+	setString("mode", c.Mode)
+	setString("dataset", c.Dataset)
+	setFloat("split", c.Split)
+	if c.Seed != nil {
+		values["seed"] = strconv.FormatInt(*c.Seed, 10)
+	}
+	setString("smoothing", c.Smoothing)
+	setFloat("alpha", c.Alpha)
+	setInt("port", c.Port)
+	setString("read-timeout", c.ReadTimeout)
+	setString("write-timeout", c.WriteTimeout)
+	setString("idle-timeout", c.IdleTimeout)
+	setInt("batch-workers", c.BatchWorkers)
+	setString("tls-cert", c.TLSCert)
+	setString("tls-key", c.TLSKey)
+	return values
+}
+
+// mergeConfig applies the file values of cfg to every flag in fs that was not
+// set explicitly, so explicit flags always win over the file.
+func mergeConfig(fs *flag.FlagSet, cfg Config) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	for name, value := range cfg.values() {
+		if explicit[name] {
+			continue
+		}
+		if err := fs.Set(name, value); err != nil {
+			return fmt.Errorf("config field for -%s: %w", name, err)
+		}
+	}
+	return nil
+}
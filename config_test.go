@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeConfigPrecedence(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	mode := fs.String("mode", "evaluate", "")
+	split := fs.Float64("split", 0.8, "")
+	port := fs.Int("port", 8080, "")
+	seed := fs.Int64("seed", 42, "")
+	if err := fs.Parse([]string{"-split", "0.6"}); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"mode": "serve", "split": 0.9, "port": 9000}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mergeConfig(fs, cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	if *mode != "serve" || *port != 9000 {
+		t.Errorf("mode = %q, port = %d; want the file values serve and 9000", *mode, *port)
+	}
+	if *split != 0.6 {
+		t.Errorf("split = %v, want the explicit flag value 0.6", *split)
+	}
+	if *seed != 42 {
+		t.Errorf("seed = %d, want the default 42", *seed)
+	}
+}
+
+func TestLoadConfigFileRejectsUnknownFields(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"mdoe": "serve"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadConfigFile(path); err == nil {
+		t.Error("loadConfigFile accepted an unknown field")
+	}
+}
+
+func TestMergeConfigInvalidValue(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Duration("read-timeout", 0, "")
+	timeout := "soon"
+	if err := mergeConfig(fs, Config{ReadTimeout: &timeout}); err == nil {
+		t.Error("mergeConfig accepted an invalid duration")
+	}
+}
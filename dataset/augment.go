@@ -0,0 +1,61 @@
+package dataset
+
+import (
+	"math/rand"
+	"strings"
+	"unicode"
+
+	"sentimentbayes/sentiment"
+)
+
+// AugmentSynonyms returns docs followed by one synthetic variant per document
+// in which every word listed in synonyms is replaced by one of its synonyms,
+// chosen at random. Lookups ignore case and surrounding punctuation, which is
+// kept in the output. Documents without any replaceable word produce no
+// variant, and variants whose text already exists are dropped, so the result
+// never contains new exact duplicates. The same seed gives the same result.
+func AugmentSynonyms(docs []sentiment.Document, synonyms map[string][]string, seed int64) []sentiment.Document {
+	lookup := make(map[string][]string, len(synonyms))
+	for word, alternatives := range synonyms {
+		lookup[strings.ToLower(word)] = alternatives
+	}
+	seen := make(map[string]struct{}, len(docs))
+	for _, doc := range docs {
+		seen[doc.Text] = struct{}{}
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	augmented := append([]sentiment.Document(nil), docs...)
+	for _, doc := range docs {
+		words := strings.Fields(doc.Text)
+		replaced := false
+		for i, word := range words {
+			start := strings.IndexFunc(word, isWordRune)
+			end := strings.LastIndexFunc(word, isWordRune)
+			if start < 0 {
+				continue
+			}
+			core := word[start : end+1]
+			alternatives := lookup[strings.ToLower(core)]
+			if len(alternatives) == 0 {
+				continue
+			}
+			words[i] = word[:start] + alternatives[rng.Intn(len(alternatives))] + word[end+1:]
+			replaced = true
+		}
+		if !replaced {
+			continue
+		}
+		text := strings.Join(words, " ")
+		if _, dup := seen[text]; dup {
+			continue
+		}
+		seen[text] = struct{}{}
+		augmented = append(augmented, sentiment.Document{ID: doc.ID, Text: text, Label: doc.Label})
+	}
+	return augmented
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,64 @@
+package dataset
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sentimentbayes/sentiment"
+)
+
+// LoadDirectory reads a dataset laid out as one subdirectory per class under
+// root, each holding one text file per document, as in the classic movie
+// review and 20 Newsgroups corpora. The subdirectory name is the label and
+// the whole file content is the text. Labels are normalized as in LoadCSV,
+// empty files are skipped, and files nested deeper than one level are
+// ignored. Documents are returned in directory then file name order.
+func LoadDirectory(root string, opts ...LoadOption) ([]sentiment.Document, error) {
+	var cfg loadConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var docs []sentiment.Document
+	classDirs := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		classDirs++
+		label := sentiment.NormalizeLabel(entry.Name())
+		dir := filepath.Join(root, entry.Name())
+		files, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if !file.Type().IsRegular() {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+			if err != nil {
+				return nil, err
+			}
+			text := strings.TrimSpace(string(data))
+			if text == "" || !cfg.accepts(text) {
+				continue
+			}
+			docs = append(docs, sentiment.Document{Text: text, Label: label})
+		}
+	}
+	if classDirs == 0 {
+		return nil, fmt.Errorf("dataset directory %s has no class subdirectories", root)
+	}
+	if len(docs) == 0 {
+		return nil, errors.New("dataset is empty")
+	}
+	return docs, nil
+}
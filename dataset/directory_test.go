@@ -0,0 +1,60 @@
+package dataset
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sentimentbayes/sentiment"
+)
+
+func TestLoadDirectory(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"Positive", "neg", "neg/nested"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, root, "Positive/b.txt", "Great fun\n")
+	writeFile(t, root, "Positive/a.txt", "Loved it")
+	writeFile(t, root, "Positive/empty.txt", "  \n")
+	writeFile(t, root, "neg/1.txt", "Awful")
+	writeFile(t, root, "neg/nested/deep.txt", "ignored")
+	writeFile(t, root, "README", "not a class")
+
+	docs, err := LoadDirectory(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Directories and files come in byte order, so "Positive" sorts before "neg".
+	want := []sentiment.Document{
+		{Text: "Loved it", Label: "positive"},
+		{Text: "Great fun", Label: "positive"},
+		{Text: "Awful", Label: "neg"},
+	}
+	if !reflect.DeepEqual(docs, want) {
+		t.Errorf("LoadDirectory = %v, want %v", docs, want)
+	}
+}
+
+func TestLoadDirectoryErrors(t *testing.T) {
+	flat := t.TempDir()
+	writeFile(t, flat, "a.txt", "text without a class directory")
+	if _, err := LoadDirectory(flat); err == nil || !strings.Contains(err.Error(), "no class subdirectories") {
+		t.Errorf("flat directory: error = %v, want a missing subdirectories error", err)
+	}
+
+	empty := t.TempDir()
+	if err := os.Mkdir(filepath.Join(empty, "positive"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDirectory(empty); err == nil {
+		t.Error("directory without documents: LoadDirectory succeeded")
+	}
+
+	if _, err := LoadDirectory(filepath.Join(empty, "missing")); err == nil {
+		t.Error("missing root: LoadDirectory succeeded")
+	}
+}
@@ -0,0 +1,131 @@
+package dataset
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"sentimentbayes/sentiment"
+)
+
+// JSONFields sets the object keys LoadJSONL reads the text and label from.
+// Empty names keep the defaults, "text" and "label". Other loaders ignore it.
+func JSONFields(text, label string) LoadOption {
+	return func(cfg *loadConfig) {
+		cfg.textField = text
+		cfg.labelField = label
+	}
+}
+
+// LoadJSONL reads documents from a JSON Lines file with one object per line.
+// The text and label are taken from the "text" and "label" keys unless
+// JSONFields says otherwise. Blank lines are ignored, labels are lowercased
+// and objects with an empty text or label are skipped, as in LoadCSV. An "id"
+// key, string or number, becomes the document's ID.
+func LoadJSONL(path string, opts ...LoadOption) ([]sentiment.Document, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadJSONLReader(file, opts...)
+}
+
+// LoadJSONLReader parses JSON Lines data read from r, following the same
+// rules as LoadJSONL.
+func LoadJSONLReader(r io.Reader, opts ...LoadOption) ([]sentiment.Document, error) {
+	cfg := loadConfig{textField: "text", labelField: "label"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.textField == "" {
+		cfg.textField = "text"
+	}
+	if cfg.labelField == "" {
+		cfg.labelField = "label"
+	}
+
+	var docs []sentiment.Document
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+		var record map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(raw), &record); err != nil {
+			return nil, fmt.Errorf("read dataset line %d: %w", line, err)
+		}
+		text, err := stringField(record, cfg.textField)
+		if err != nil {
+			return nil, fmt.Errorf("read dataset line %d: %w", line, err)
+		}
+		label, err := stringField(record, cfg.labelField)
+		if err != nil {
+			return nil, fmt.Errorf("read dataset line %d: %w", line, err)
+		}
+		id, err := idField(record, "id")
+		if err != nil {
+			return nil, fmt.Errorf("read dataset line %d: %w", line, err)
+		}
+		text = strings.TrimSpace(text)
+		label = strings.TrimSpace(label)
+		if text == "" || label == "" || !cfg.accepts(text) {
+			continue
+		}
+		docs = append(docs, sentiment.Document{
+			ID:    id,
+			Text:  text,
+			Label: sentiment.NormalizeLabel(label),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read dataset line %d: %w", line+1, err)
+	}
+	if len(docs) == 0 {
+		return nil, errors.New("dataset is empty")
+	}
+	return docs, nil
+}
+
+// stringField returns the string stored under key, or "" when the key is
+// missing or null.
+func stringField(record map[string]json.RawMessage, key string) (string, error) {
+	raw, ok := record[key]
+	if !ok {
+		return "", nil
+	}
+	var value *string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("field %q is not a string", key)
+	}
+	if value == nil {
+		return "", nil
+	}
+	return *value, nil
+}
+
+// idField returns the string or number stored under key as a string, or ""
+// when the key is missing or null.
+func idField(record map[string]json.RawMessage, key string) (string, error) {
+	raw, ok := record[key]
+	if !ok {
+		return "", nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return number.String(), nil
+	}
+	id, err := stringField(record, key)
+	if err != nil {
+		return "", fmt.Errorf("field %q is not a string or number", key)
+	}
+	return id, nil
+}
@@ -0,0 +1,56 @@
+package dataset
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"sentimentbayes/sentiment"
+)
+
+func TestLoadJSONLReaderCustomFields(t *testing.T) {
+	input := `{"body": "Loved it", "sentiment": "POSITIVE"}
+
+{"body": "Hated it", "sentiment": " Negative ", "text": "ignored"}
+{"body": "", "sentiment": "negative"}
+{"body": "No label"}
+`
+	docs, err := LoadJSONLReader(strings.NewReader(input), JSONFields("body", "sentiment"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sentiment.Document{
+		{Text: "Loved it", Label: "positive"},
+		{Text: "Hated it", Label: "negative"},
+	}
+	if !reflect.DeepEqual(docs, want) {
+		t.Errorf("LoadJSONLReader = %v, want %v", docs, want)
+	}
+}
+
+func TestLoadJSONLReaderDefaultFields(t *testing.T) {
+	input := `{"id": 7, "text": "Great", "label": "Positive", "body": "ignored"}` + "\n"
+	for _, opts := range [][]LoadOption{nil, {JSONFields("", "")}} {
+		docs, err := LoadJSONLReader(strings.NewReader(input), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []sentiment.Document{{ID: "7", Text: "Great", Label: "positive"}}
+		if !reflect.DeepEqual(docs, want) {
+			t.Errorf("LoadJSONLReader = %v, want %v", docs, want)
+		}
+	}
+}
+
+func TestLoadJSONLReaderErrors(t *testing.T) {
+	tests := map[string]string{
+		"malformed line":   `{"text": "ok", "label": "positive"}` + "\n{not json}\n",
+		"non-string label": `{"text": "ok", "label": 1}` + "\n",
+		"empty":            "\n\n",
+	}
+	for name, input := range tests {
+		if _, err := LoadJSONLReader(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: LoadJSONLReader succeeded, want an error", name)
+		}
+	}
+}
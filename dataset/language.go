@@ -0,0 +1,85 @@
+package dataset
+
+import (
+	"strings"
+	"unicode"
+)
+
+// languageProfiles lists the most frequent character trigrams of each
+// supported language, most frequent first. Words are padded with spaces so
+// that word starts and ends form their own trigrams.
+var languageProfiles = map[string][]string{
+	"en": {
+		" th", "the", "he ", "and", " an", "nd ", "ing", "ng ", " to", "to ",
+		" of", "of ", "ed ", "er ", " is", "is ", " in", "in ", "at ", "ion",
+		"tio", "hat", "thi", "his", " it", "it ", "was", " wa", "for", " fo",
+		" be", "ve ", "ly ", "all", "not", " no", "ot ", "but", " bu", "ery",
+		"ver", "wit", "ith", " wi", " my", "my ", "you", " yo", "ou ", "re ",
+	},
+	"es": {
+		" de", "de ", "que", " qu", "ue ", " la", "la ", " el", "el ", "os ",
+		" en", "en ", "as ", " co", "ión", "ció", " lo", "los", "ado", " pa",
+		"par", "ara", "con", " no", "no ", " es", "est", "una", " un", " po",
+		"por", "muy", " mu", "uy ", "ero", " pe", "per", "ien", "lla", "ón ",
+		"mos", "sta", "nte", "te ", " su", "su ", "del", "ada", "do ", "ez ",
+	},
+	"fr": {
+		" de", "de ", " le", "le ", "es ", " la", "la ", "les", " et", "et ",
+		"ent", "nt ", " qu", "que", "ue ", "ion", " co", " pa", "pas", " ne",
+		"ne ", "our", "pou", " po", "ais", "ait", "est", " es", "st ", " un",
+		"une", "ur ", "eur", " ce", "ce ", "tre", "ès ", "trè", "re ", "des",
+		"ans", "ont", "ous", "vou", " vo", "eme", "men", "ell", "lle", "au ",
+	},
+	"de": {
+		"en ", "er ", " de", "der", "ie ", "die", " di", "ch ", "ein", " ei",
+		"sch", "ich", "nd ", "und", " un", "den", "cht", "ine", " ge", "ht ",
+		" ni", "nic", " is", "ist", "st ", "das", " da", "te ", "ung", " mi",
+		"mit", "seh", "ehr", "hr ", "ber", "ges", "auf", " au", "hen", "eit",
+		"ler", "ach", "wir", " wi", "nen", "ten", "gut", "sie", " si", "zu ",
+	},
+}
+
+// DetectLanguage guesses the language of text by comparing its character
+// trigrams against small built-in frequency profiles. It returns an ISO 639-1
+// code ("en", "es", "fr" or "de"), or "" when no profile matches.
+func DetectLanguage(text string) string {
+	trigrams := extractTrigrams(text)
+	if len(trigrams) == 0 {
+		return ""
+	}
+
+	best := ""
+	bestScore := 0
+	for _, lang := range []string{"en", "es", "fr", "de"} {
+		profile := languageProfiles[lang]
+		ranks := make(map[string]int, len(profile))
+		for i, gram := range profile {
+			if _, ok := ranks[gram]; !ok {
+				ranks[gram] = len(profile) - i
+			}
+		}
+		score := 0
+		for _, gram := range trigrams {
+			score += ranks[gram]
+		}
+		if score > bestScore {
+			best = lang
+			bestScore = score
+		}
+	}
+	return best
+}
+
+func extractTrigrams(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	var trigrams []string
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			trigrams = append(trigrams, string(runes[i:i+3]))
+		}
+	}
+	return trigrams
+}
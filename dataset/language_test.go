@@ -0,0 +1,54 @@
+package dataset
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"The food was not worth the price at all", "en"},
+		{"La comida estaba fría y el servicio era muy lento", "es"},
+		{"Le service était très lent et la nourriture froide", "fr"},
+		{"Das Essen war kalt und der Service nicht gut", "de"},
+		{"12345 !!!", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectLanguage(tt.text); got != tt.want {
+			t.Errorf("DetectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFilterLanguageDropsOtherLanguages(t *testing.T) {
+	data := `text,label
+"I love this phone, it is fantastic",positive
+"Me encanta este teléfono, es fantástico y muy bueno",positive
+"The screen cracked within a day",negative
+"La pantalla se rompió en un día, que mal producto",negative
+12345,negative
+`
+	docs, err := LoadCSVReader(strings.NewReader(data), FilterLanguage("EN"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var texts []string
+	for _, doc := range docs {
+		texts = append(texts, doc.Text)
+	}
+	want := []string{"I love this phone, it is fantastic", "The screen cracked within a day", "12345"}
+	if strings.Join(texts, "|") != strings.Join(want, "|") {
+		t.Errorf("kept %q, want %q (undetectable rows are kept)", texts, want)
+	}
+
+	all, err := LoadCSVReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 5 {
+		t.Errorf("without the filter loaded %d rows, want 5", len(all))
+	}
+}
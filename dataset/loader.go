@@ -8,25 +8,97 @@ import (
 	"math"
 	"math/rand"
 	"os"
+	"sort"
 	"strings"
 
 	"sentimentbayes/sentiment"
 )
 
+// LoadOption configures optional filtering performed while loading.
+type LoadOption func(*loadConfig)
+
+type loadConfig struct {
+	language   string
+	textField  string
+	labelField string
+}
+
+// FilterLanguage drops rows whose text is detected as a language other than
+// code (an ISO 639-1 code such as "en"). Rows whose language cannot be
+// determined are kept. See DetectLanguage for the supported languages.
+func FilterLanguage(code string) LoadOption {
+	return func(cfg *loadConfig) {
+		cfg.language = strings.ToLower(code)
+	}
+}
+
+func (cfg loadConfig) accepts(text string) bool {
+	if cfg.language == "" {
+		return true
+	}
+	detected := DetectLanguage(text)
+	return detected == "" || detected == cfg.language
+}
+
 // LoadCSV reads text,label pairs from a CSV file.
 // The first row can optionally be a header containing "text" and "label".
-func LoadCSV(path string) ([]sentiment.Document, error) {
+// A header may also name an "id" column after them, whose values become the
+// documents' IDs.
+func LoadCSV(path string, opts ...LoadOption) ([]sentiment.Document, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
+	return LoadCSVReader(file, opts...)
+}
+
+// LoadCSVReader parses text,label pairs from CSV data read from r, following
+// the same rules as LoadCSV.
+func LoadCSVReader(r io.Reader, opts ...LoadOption) ([]sentiment.Document, error) {
+	docs, _, err := loadCSV(r, opts)
+	return docs, err
+}
+
+// SkippedRow describes a CSV row that was not turned into a document.
+type SkippedRow struct {
+	Line   int
+	Reason string
+}
+
+// LoadReport summarizes what happened to every row while loading a dataset.
+type LoadReport struct {
+	Rows    int
+	Loaded  int
+	Skipped []SkippedRow
+}
+
+// LoadCSVVerbose behaves like LoadCSV but also reports which rows were
+// skipped and why. The report is returned even when loading fails.
+func LoadCSVVerbose(path string, opts ...LoadOption) ([]sentiment.Document, LoadReport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, LoadReport{}, err
+	}
+	defer file.Close()
+	return loadCSV(file, opts)
+}
+
+func loadCSV(r io.Reader, opts []LoadOption) ([]sentiment.Document, LoadReport, error) {
+	var cfg loadConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
 
-	reader := csv.NewReader(file)
+	reader := csv.NewReader(r)
 	reader.TrimLeadingSpace = true
+	// Rows with too few fields are reported and skipped rather than aborting the load.
+	reader.FieldsPerRecord = -1
 
 	var docs []sentiment.Document
+	var report LoadReport
 	row := 0
+	idColumn := -1
 
 	for {
 		record, err := reader.Read()
@@ -34,33 +106,52 @@ func LoadCSV(path string) ([]sentiment.Document, error) {
 			break
 		}
 		if err != nil {
-			return nil, fmt.Errorf("read dataset line %d: %w", row+1, err)
+			return nil, report, fmt.Errorf("read dataset line %d: %w", row+1, err)
 		}
+		line, _ := reader.FieldPos(0)
+		report.Rows++
 		if len(record) < 2 {
+			report.skip(line, fmt.Sprintf("expected at least 2 fields, got %d", len(record)))
 			continue
 		}
 		if row == 0 && looksLikeHeader(record) {
+			idColumn = headerIDColumn(record)
+			report.Rows--
 			row++
 			continue
 		}
 
 		text := strings.TrimSpace(record[0])
 		label := strings.TrimSpace(record[1])
-		if text == "" || label == "" {
-			row++
-			continue
+		switch {
+		case text == "":
+			report.skip(line, "empty text")
+		case label == "":
+			report.skip(line, "empty label")
+		case !cfg.accepts(text):
+			report.skip(line, fmt.Sprintf("language %q does not match %q", DetectLanguage(text), cfg.language))
+		default:
+			doc := sentiment.Document{
+				Text:  text,
+				Label: sentiment.NormalizeLabel(label),
+			}
+			if idColumn >= 0 && idColumn < len(record) {
+				doc.ID = strings.TrimSpace(record[idColumn])
+			}
+			docs = append(docs, doc)
+			report.Loaded++
 		}
-		docs = append(docs, sentiment.Document{
-			Text:  text,
-			Label: strings.ToLower(label),
-		})
 		row++
 	}
 
 	if len(docs) == 0 {
-		return nil, errors.New("dataset is empty")
+		return nil, report, errors.New("dataset is empty")
 	}
-	return docs, nil
+	return docs, report, nil
+}
+
+func (r *LoadReport) skip(line int, reason string) {
+	r.Skipped = append(r.Skipped, SkippedRow{Line: line, Reason: reason})
 }
 
 // SplitDataset shuffles the dataset and splits it into train/test slices.
@@ -94,6 +185,70 @@ func SplitDataset(docs []sentiment.Document, trainRatio float64, seed int64) ([]
 	return train, test
 }
 
+// StratifiedSplit splits each class independently with trainRatio and then
+// recombines the parts, so every class with at least two samples appears in
+// both the train and test slices in proportion to its size. Classes with a
+// single sample are placed in the train slice.
+func StratifiedSplit(docs []sentiment.Document, trainRatio float64, seed int64) ([]sentiment.Document, []sentiment.Document) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	if trainRatio <= 0 || trainRatio >= 1 {
+		trainRatio = 0.8
+	}
+
+	byLabel := make(map[string][]sentiment.Document)
+	for _, doc := range docs {
+		byLabel[doc.Label] = append(byLabel[doc.Label], doc)
+	}
+	labels := make([]string, 0, len(byLabel))
+	for label := range byLabel {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	rng := rand.New(rand.NewSource(seed))
+	var train, test []sentiment.Document
+	for _, label := range labels {
+		group := append([]sentiment.Document(nil), byLabel[label]...)
+		rng.Shuffle(len(group), func(i, j int) {
+			group[i], group[j] = group[j], group[i]
+		})
+		if len(group) == 1 {
+			train = append(train, group...)
+			continue
+		}
+		trainSize := int(math.Round(trainRatio * float64(len(group))))
+		if trainSize <= 0 {
+			trainSize = 1
+		}
+		if trainSize >= len(group) {
+			trainSize = len(group) - 1
+		}
+		train = append(train, group[:trainSize]...)
+		test = append(test, group[trainSize:]...)
+	}
+
+	rng.Shuffle(len(train), func(i, j int) {
+		train[i], train[j] = train[j], train[i]
+	})
+	rng.Shuffle(len(test), func(i, j int) {
+		test[i], test[j] = test[j], test[i]
+	})
+	return train, test
+}
+
+// headerIDColumn returns the index of the "id" column in a header row after
+// the text and label columns, or -1 when there is none.
+func headerIDColumn(header []string) int {
+	for i := 2; i < len(header); i++ {
+		if strings.EqualFold(strings.TrimSpace(header[i]), "id") {
+			return i
+		}
+	}
+	return -1
+}
+
 func looksLikeHeader(record []string) bool {
 	if len(record) < 2 {
 		return false
@@ -0,0 +1,115 @@
+package dataset
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"sentimentbayes/sentiment"
+)
+
+func TestLoadCSVReader(t *testing.T) {
+	data := `text,label
+"Great phone, love it", Positive
+The screen cracked,NEGATIVE
+,negative
+only one field
+`
+	docs, err := LoadCSVReader(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sentiment.Document{
+		{Text: "Great phone, love it", Label: "positive"},
+		{Text: "The screen cracked", Label: "negative"},
+	}
+	if !reflect.DeepEqual(docs, want) {
+		t.Errorf("LoadCSVReader = %+v, want %+v", docs, want)
+	}
+}
+
+func TestLoadCSVReaderWithoutHeader(t *testing.T) {
+	docs, err := LoadCSVReader(strings.NewReader("good,positive\nbad,negative\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 2 || docs[0].Text != "good" {
+		t.Errorf("LoadCSVReader = %+v, want both rows kept", docs)
+	}
+}
+
+func TestLoadCSVReaderErrors(t *testing.T) {
+	for _, data := range []string{"", "text,label\n", "text,label\n,positive\n"} {
+		if _, err := LoadCSVReader(strings.NewReader(data)); err == nil {
+			t.Errorf("LoadCSVReader(%q) succeeded, want an empty dataset error", data)
+		}
+	}
+	if _, err := LoadCSVReader(strings.NewReader("\"unterminated,positive\n")); err == nil {
+		t.Error("LoadCSVReader accepted malformed CSV")
+	}
+}
+
+func TestLabelsNormalizedAcrossEntryPaths(t *testing.T) {
+	docs, err := LoadCSVReader(strings.NewReader("text,label\nLoved it,Positive\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb := sentiment.NewNaiveBayesClassifier()
+	nb.TrainBatch(docs)
+	nb.Train("Great fun", " POSITIVE ")
+	nb.Train("Awful", "negative")
+
+	_, probs := nb.Predict("loved")
+	if len(probs) != 2 {
+		t.Errorf("classes = %v, want positive and negative only", probs)
+	}
+	if _, ok := probs["positive"]; !ok {
+		t.Errorf("classes = %v, want a single lowercase positive class", probs)
+	}
+}
+
+func TestDocumentIDsSurviveEvaluation(t *testing.T) {
+	train, err := LoadCSVReader(strings.NewReader("text,label\ngreat food,positive\nawful food,negative\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	csvDocs, err := LoadCSVReader(strings.NewReader(`text,label,id
+great staff,positive,row-1
+awful staff,negative,row-2
+great mess,negative,row-3
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonlDocs, err := LoadJSONLReader(strings.NewReader(`{"id": 42, "text": "awful view", "label": "positive"}
+{"text": "great view", "label": "positive"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	test := append(csvDocs, jsonlDocs...)
+	wantIDs := []string{"row-1", "row-2", "row-3", "42", ""}
+	for i, doc := range test {
+		if doc.ID != wantIDs[i] {
+			t.Errorf("doc %d (%q) ID = %q, want %q", i, doc.Text, doc.ID, wantIDs[i])
+		}
+	}
+
+	nb := sentiment.NewNaiveBayesClassifier()
+	nb.TrainBatch(train)
+	detailed := sentiment.EvaluateDetailed(nb, test)
+	var got []string
+	for _, result := range detailed.Misclassifications() {
+		got = append(got, result.ID)
+	}
+	if want := []string{"row-3", "42"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("misclassified IDs = %q, want %q", got, want)
+	}
+	ids := make(map[string][]string)
+	for _, cell := range sentiment.ExplainConfusion(detailed, 5) {
+		ids[cell.Actual+" -> "+cell.Predicted] = cell.ExampleIDs
+	}
+	if want := map[string][]string{"negative -> positive": {"row-3"}, "positive -> negative": {"42"}}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("confusion example IDs = %q, want %q", ids, want)
+	}
+}
@@ -0,0 +1,69 @@
+package dataset
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"sentimentbayes/sentiment"
+)
+
+// LoadParallel zips a file of texts and a file of labels, one per line, into
+// documents. Both files must have the same number of lines. Labels are
+// lowercased and pairs with an empty text or label are skipped, as in LoadCSV.
+func LoadParallel(textPath, labelPath string, opts ...LoadOption) ([]sentiment.Document, error) {
+	var cfg loadConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	texts, err := readLines(textPath)
+	if err != nil {
+		return nil, err
+	}
+	labels, err := readLines(labelPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(texts) != len(labels) {
+		return nil, fmt.Errorf("line count mismatch: %s has %d lines, %s has %d", textPath, len(texts), labelPath, len(labels))
+	}
+
+	var docs []sentiment.Document
+	for i := range texts {
+		text := strings.TrimSpace(texts[i])
+		label := strings.TrimSpace(labels[i])
+		if text == "" || label == "" || !cfg.accepts(text) {
+			continue
+		}
+		docs = append(docs, sentiment.Document{
+			Text:  text,
+			Label: sentiment.NormalizeLabel(label),
+		})
+	}
+	if len(docs) == 0 {
+		return nil, errors.New("dataset is empty")
+	}
+	return docs, nil
+}
+
+func readLines(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return lines, nil
+}
@@ -0,0 +1,50 @@
+package dataset
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sentimentbayes/sentiment"
+)
+
+// writeFile writes content to name inside dir and returns its path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadParallel(t *testing.T) {
+	dir := t.TempDir()
+	texts := writeFile(t, dir, "texts.txt", "Loved it\n\nHated it\nMeh\n")
+	labels := writeFile(t, dir, "labels.txt", "Positive\nnegative\n NEGATIVE \n\n")
+
+	docs, err := LoadParallel(texts, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []sentiment.Document{
+		{Text: "Loved it", Label: "positive"},
+		{Text: "Hated it", Label: "negative"},
+	}
+	if !reflect.DeepEqual(docs, want) {
+		t.Errorf("LoadParallel = %v, want %v", docs, want)
+	}
+}
+
+func TestLoadParallelLineCountMismatch(t *testing.T) {
+	dir := t.TempDir()
+	texts := writeFile(t, dir, "texts.txt", "Loved it\nHated it\nMeh\n")
+	labels := writeFile(t, dir, "labels.txt", "positive\nnegative\n")
+
+	_, err := LoadParallel(texts, labels)
+	if err == nil || !strings.Contains(err.Error(), "line count mismatch") {
+		t.Errorf("LoadParallel error = %v, want a line count mismatch", err)
+	}
+}
@@ -0,0 +1,60 @@
+package dataset
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"sentimentbayes/sentiment"
+)
+
+// IsURL reports whether path is an http:// or https:// URL rather than a
+// local file path.
+func IsURL(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// LoadURL downloads a CSV dataset and parses it like LoadCSV, streaming the
+// response body into the CSV reader. Each attempt is bounded by timeout.
+// Network errors and 5xx responses are retried up to retries more times with
+// a short linear backoff; other non-200 responses fail immediately.
+func LoadURL(url string, timeout time.Duration, retries int, opts ...LoadOption) ([]sentiment.Document, error) {
+	client := &http.Client{Timeout: timeout}
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt) * 500 * time.Millisecond)
+		}
+		docs, retry, err := fetchCSV(client, url, opts)
+		if err == nil {
+			return docs, nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// fetchCSV performs a single download attempt. The boolean reports whether
+// the failure is worth retrying.
+func fetchCSV(client *http.Client, url string, opts []LoadOption) ([]sentiment.Document, bool, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, true, fmt.Errorf("fetch dataset %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("fetch dataset %s: unexpected status %s", url, resp.Status)
+		return nil, resp.StatusCode >= 500, err
+	}
+	docs, err := LoadCSVReader(resp.Body, opts...)
+	if err != nil {
+		var netErr interface{ Timeout() bool }
+		return nil, errors.As(err, &netErr) && netErr.Timeout(), fmt.Errorf("fetch dataset %s: %w", url, err)
+	}
+	return docs, false, nil
+}
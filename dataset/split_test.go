@@ -0,0 +1,83 @@
+package dataset
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"sentimentbayes/sentiment"
+)
+
+// labeledDocs returns n documents labeled label with distinct texts.
+func labeledDocs(label string, n int) []sentiment.Document {
+	docs := make([]sentiment.Document, n)
+	for i := range docs {
+		docs[i] = sentiment.Document{Text: fmt.Sprintf("%s text %d", label, i), Label: label}
+	}
+	return docs
+}
+
+func countLabels(docs []sentiment.Document) map[string]int {
+	counts := make(map[string]int)
+	for _, doc := range docs {
+		counts[doc.Label]++
+	}
+	return counts
+}
+
+func TestStratifiedSplitKeepsClassProportions(t *testing.T) {
+	docs := append(labeledDocs("positive", 40), labeledDocs("negative", 10)...)
+	docs = append(docs, labeledDocs("neutral", 5)...)
+
+	train, test := StratifiedSplit(docs, 0.8, 7)
+
+	wantTrain := map[string]int{"positive": 32, "negative": 8, "neutral": 4}
+	wantTest := map[string]int{"positive": 8, "negative": 2, "neutral": 1}
+	if got := countLabels(train); !reflect.DeepEqual(got, wantTrain) {
+		t.Errorf("train label counts = %v, want %v", got, wantTrain)
+	}
+	if got := countLabels(test); !reflect.DeepEqual(got, wantTest) {
+		t.Errorf("test label counts = %v, want %v", got, wantTest)
+	}
+	if len(train)+len(test) != len(docs) {
+		t.Errorf("split lost documents: %d + %d != %d", len(train), len(test), len(docs))
+	}
+}
+
+func TestStratifiedSplitDeterministic(t *testing.T) {
+	docs := append(labeledDocs("positive", 12), labeledDocs("negative", 9)...)
+
+	trainA, testA := StratifiedSplit(docs, 0.7, 42)
+	trainB, testB := StratifiedSplit(docs, 0.7, 42)
+	if !reflect.DeepEqual(trainA, trainB) || !reflect.DeepEqual(testA, testB) {
+		t.Error("the same seed produced different splits")
+	}
+
+	trainC, _ := StratifiedSplit(docs, 0.7, 43)
+	if reflect.DeepEqual(trainA, trainC) {
+		t.Error("different seeds produced identical train orders")
+	}
+}
+
+func TestStratifiedSplitSmallInputs(t *testing.T) {
+	tests := []struct {
+		name      string
+		docs      []sentiment.Document
+		wantTrain int
+		wantTest  int
+	}{
+		{"empty", nil, 0, 0},
+		{"single document", labeledDocs("positive", 1), 1, 0},
+		{"single class", labeledDocs("positive", 5), 4, 1},
+		{"two per class", append(labeledDocs("positive", 2), labeledDocs("negative", 2)...), 2, 2},
+		{"singleton class", append(labeledDocs("positive", 4), labeledDocs("negative", 1)...), 4, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			train, test := StratifiedSplit(tt.docs, 0.8, 1)
+			if len(train) != tt.wantTrain || len(test) != tt.wantTest {
+				t.Errorf("got %d train / %d test, want %d / %d", len(train), len(test), tt.wantTrain, tt.wantTest)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"sentimentbayes/sentiment"
+)
+
+type compareMember struct {
+	Name          string         `json:"name"`
+	Label         string         `json:"label"`
+	Probabilities probabilityMap `json:"probabilities"`
+}
+
+type compareResponse struct {
+	Members       []compareMember `json:"members"`
+	Label         string          `json:"label"`
+	Probabilities probabilityMap  `json:"probabilities"`
+	RequestID     string          `json:"request_id,omitempty"`
+}
+
+// buildEnsemble combines the serving classifier, named "primary", with one
+// member per snapshot path in the comma-separated list. It returns nil when
+// the list is empty. mode selects how the members are combined.
+func buildEnsemble(primary *sentiment.NaiveBayesClassifier, paths, mode string) (*sentiment.Ensemble, error) {
+	if strings.TrimSpace(paths) == "" {
+		return nil, nil
+	}
+	aggregation, err := sentiment.ParseAggregationMode(mode)
+	if err != nil {
+		return nil, err
+	}
+	members := []sentiment.EnsembleMember{{Name: "primary", Model: primary}}
+	for _, path := range strings.Split(paths, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		model := sentiment.NewNaiveBayesClassifier()
+		if _, err := loadSnapshotFromDisk(model, path, nil); err != nil {
+			return nil, err
+		}
+		members = append(members, sentiment.EnsembleMember{Name: path, Model: model})
+	}
+	ensemble := sentiment.NewEnsemble(members...)
+	ensemble.Mode = aggregation
+	return ensemble, nil
+}
+
+// compareHandler classifies a text with every ensemble member and reports
+// each member's prediction next to the combined one, which shows which
+// member pulls the ensemble away from the others.
+func compareHandler(ensemble *sentiment.Ensemble) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req classifyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if req.Text == "" {
+			http.Error(w, "text is required", http.StatusBadRequest)
+			return
+		}
+
+		members, label, probs := ensemble.PredictEach(req.Text)
+		resp := compareResponse{
+			Members:       make([]compareMember, len(members)),
+			Label:         label,
+			Probabilities: roundProbabilities(probs),
+			RequestID:     requestIDFrom(r.Context()),
+		}
+		for i, m := range members {
+			resp.Members[i] = compareMember{Name: m.Name, Label: m.Label, Probabilities: roundProbabilities(m.Probabilities)}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
@@ -1,14 +1,24 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
+	"math"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"regexp"
+	"runtime"
 	"sort"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 
 	"sentimentbayes/dataset"
@@ -16,73 +26,490 @@ import (
 )
 
 var (
-	datasetPath      = flag.String("dataset", "data/sample.csv", "Path to CSV dataset with text,label columns")
+	configPath       = flag.String("config", "", "Optional JSON config file; explicit flags override its values")
+	datasetPath      = flag.String("dataset", "data/sample.csv", "Path to CSV dataset with text,label columns, a .jsonl file with one object per line, or a directory with one subdirectory of text files per label")
+	datasetTimeout   = flag.Duration("dataset-timeout", 30*time.Second, "Timeout for each attempt when -dataset or -load-snapshot is an http(s) URL")
+	datasetRetries   = flag.Int("dataset-retries", 2, "Retries after a failed download when -dataset is an http(s) URL")
+	datasetLanguage  = flag.String("language", "", "Optional ISO 639-1 code; dataset rows detected as another language are dropped")
+	jsonTextField    = flag.String("json-text-field", "text", "Object key holding the text when -dataset is a .jsonl file")
+	jsonLabelField   = flag.String("json-label-field", "label", "Object key holding the label when -dataset is a .jsonl file")
+	fallbackDataset  = flag.String("fallback-dataset", "", "Dataset tried when -dataset cannot be loaded, before the built-in dataset")
+	strictDataset    = flag.Bool("strict-dataset", false, "Fail instead of falling back to the built-in dataset when neither -dataset nor -fallback-dataset can be loaded")
+	testDataset      = flag.String("test-dataset", "", "Labeled dataset evaluate and compare modes test on, instead of splitting -dataset")
 	splitRatio       = flag.Float64("split", 0.8, "Train/test split ratio for evaluation mode")
 	randomSeed       = flag.Int64("seed", time.Now().UnixNano(), "Random seed used when shuffling the dataset")
-	mode             = flag.String("mode", "demo", "demo|classify|evaluate|serve")
+	mode             = flag.String("mode", "demo", "demo|classify|evaluate|serve|predict-file|validate|selftest|vocab|compare")
 	textInput        = flag.String("text", "", "Text to classify when using classify mode")
+	inputPath        = flag.String("input", "", "File with one text per line to classify in predict-file mode")
+	outputPath       = flag.String("output", "", "Where predict-file and vocab modes write their output (default stdout; .gz paths are gzip-compressed)")
+	outputFormat     = flag.String("output-format", "csv", "Prediction output format for predict-file mode: csv|jsonl")
+	probFormat       = flag.String("probability-format", "float", "JSON encoding of probabilities: float (shortest, may use exponents like 5e-17) or fixed (plain decimals)")
+	precision        = flag.Int("precision", 2, "Decimal places for printed probabilities; when set explicitly, JSON probabilities are rounded too")
+	vocabCounts      = flag.Bool("vocab-counts", false, "Include total token counts in vocab mode output")
 	port             = flag.Int("port", 8080, "Port for the HTTP server when using serve mode")
-	loadSnapshotPath = flag.String("load-snapshot", "", "Optional path to a JSON snapshot to load before running")
+	batchWorkers     = flag.Int("batch-workers", runtime.NumCPU(), "Worker goroutines used by /batch for large batches")
+	batchTimeout     = flag.Duration("batch-timeout", 2*time.Minute, "Read and write timeout for /batch requests, overriding -read-timeout and -write-timeout")
+	tlsCert          = flag.String("tls-cert", "", "TLS certificate file; serve mode uses HTTPS when both -tls-cert and -tls-key are set")
+	tlsKey           = flag.String("tls-key", "", "TLS private key file for serve mode")
+	feedbackWeight   = flag.Float64("feedback-weight", 0, "Enable POST /train and /correct in serve mode, counting each submitted example this many times (0 disables; large values can make the model drift)")
+	gzipResponses    = flag.Bool("gzip", true, "Gzip-compress serve mode responses for clients that accept it")
+	gzipMinSize      = flag.Int("gzip-min-size", 1024, "Smallest response body in bytes that -gzip compresses")
+	cacheSize        = flag.Int("cache-size", 0, "Cache predictions for this many recently seen distinct texts (0 disables)")
+	predictionsLog   = flag.String("predictions-log", "", "Optional JSON Lines file that serve mode appends every /classify prediction to")
+	readTimeout      = flag.Duration("read-timeout", 10*time.Second, "Maximum duration for reading an entire request in serve mode")
+	writeTimeout     = flag.Duration("write-timeout", 30*time.Second, "Maximum duration before timing out writes of a response in serve mode")
+	idleTimeout      = flag.Duration("idle-timeout", 120*time.Second, "Maximum time to keep idle keep-alive connections open in serve mode")
+	loadSnapshotPath = flag.String("load-snapshot", "", "Optional JSON snapshot to load before running: a file path, - for stdin, or an http(s) URL; explicitly set option flags override its saved options")
+	ensembleSnaps    = flag.String("ensemble-snapshots", "", "Comma-separated snapshots combined with the served model into an ensemble exposed by POST /compare in serve mode")
+	ensembleMode     = flag.String("ensemble-mode", "mean", "How -ensemble-snapshots members are combined: mean|vote|max")
+	compareSnapshot  = flag.String("compare-snapshot", "", "Second snapshot evaluated against -load-snapshot in compare mode")
+	quantizeScale    = flag.Int("quantize", 0, "After training, divide all counts by this factor and round them to shrink snapshots (0 disables)")
 	saveSnapshotPath = flag.String("save-snapshot", "", "Optional path to write the trained model snapshot (demo|classify|serve)")
+	checkpointEvery  = flag.Int("checkpoint-every", 0, "Write a snapshot to -checkpoint-path after every N training documents (0 disables)")
+	checkpointPath   = flag.String("checkpoint-path", "checkpoint.json", "Where -checkpoint-every writes its snapshots")
+	resumeTraining   = flag.Bool("resume", false, "With -load-snapshot of a checkpoint and -continue-training, skip the dataset documents the checkpoint already contains")
+	trainTimeout     = flag.Duration("train-timeout", 0, "Stop training after this long and use the partially trained model (0 disables)")
 	continueTraining = flag.Bool("continue-training", false, "Train on the dataset even when -load-snapshot is provided")
+	finetuneDataset  = flag.String("finetune-dataset", "", "With -load-snapshot and -continue-training, add this dataset's counts to the snapshot instead of training on -dataset")
+	rejectEmpty      = flag.Bool("reject-empty", false, "Answer /classify with 422 instead of a warning when the text produces no tokens")
+	canaryText       = flag.String("canary-text", "this product is great", "Sentence classified by /readyz to verify the model can predict")
+	smoothing        = flag.String("smoothing", "", "Smoothing strategy: laplace|lidstone|jeffreys|none (default laplace, or the snapshot's strategy)")
+	smoothingAlpha   = flag.Float64("alpha", 1, "Additive constant used by -smoothing lidstone")
+	priorWeight      = flag.Float64("prior-weight", 1, "Multiplier applied to the log-prior term when predicting")
+	defaultLabel     = flag.String("default-label", "", "Label returned when no class can be predicted")
+	sublinearTF      = flag.Bool("sublinear-tf", false, "Scale per-document term frequencies to log(1+count) during training (same as -tf-mode log)")
+	tfMode           = flag.String("tf-mode", "count", "How repeated tokens in a training document are counted: count|binary|log")
+	stratified       = flag.Bool("stratified", false, "Split per class in evaluate and compare modes so every class appears in train and test")
+	positionFeatures = flag.Bool("position-features", false, "Add start/end position features for the first and last tokens")
+	maxVocabSize     = flag.Int("max-vocab", 0, "Maximum vocabulary size during training; rarest tokens are evicted (0 disables)")
+	minDocFreq       = flag.Int("min-df", 0, "Drop tokens found in fewer than this many training documents (0 disables)")
+	goodTuringOOV    = flag.Bool("good-turing-oov", false, "Estimate per-class unseen-token probabilities with Good-Turing instead of flat smoothing")
+	ngramMax         = flag.Int("ngrams", 1, "Highest n-gram order used as features (1 = unigrams only)")
+	maxTokenRepeats  = flag.Int("max-token-repeats", 0, "Score at most this many occurrences of any one token when predicting, against keyword stuffing (0 disables)")
+	sentenceBreaks   = flag.Bool("sentence-breaks", false, "Add a sent_break feature for every sentence-ending run of . ! or ?")
+	coocWindow       = flag.Int("cooc-window", 0, "Add cooc_a_b features for word pairs at most this many positions apart (0 disables)")
+	bigramWeight     = flag.Float64("bigram-weight", 1, "Multiplier applied to bigram features when predicting")
+	neutralBand      = flag.Float64("neutral-band", 0, "Report \"neutral\" when the top probability is within this distance of 0.5 (0 disables)")
+	minKnownFraction = flag.Float64("min-known-fraction", 0, "Answer -abstain-label when less than this fraction of the input tokens is in the vocabulary (0 disables)")
+	abstainLabel     = flag.String("abstain-label", "unknown", "Label reported when -min-known-fraction rejects a prediction")
+	classWeights     = flag.String("class-weights", "", "Comma-separated label=weight pairs for weighted accuracy in evaluate mode (e.g. negative=3,positive=1)")
+	showErrors       = flag.Int("show-errors", 0, "Show up to N misclassified example texts per confusion cell in evaluate mode")
+	stripHTML        = flag.Bool("strip-html", false, "Strip HTML tags and decode entities before tokenizing")
+	splitIdents      = flag.Bool("split-identifiers", false, "Split camelCase and PascalCase identifiers into separate tokens")
+	collapseRepeats  = flag.Int("collapse-repeats", 0, "Collapse characters repeated 3+ times to this many (1 or 2; 0 disables)")
+	tokenAllow       = flag.String("token-allow", "", "Regular expression tokens must match to be used (e.g. ^[a-z]+$)")
+	maskTokens       = flag.String("mask-tokens", "", "Comma-separated tokens Predict ignores without retraining (not saved in snapshots)")
+	tokenDeny        = flag.String("token-deny", "", "Regular expression; matching tokens and whitespace-separated words are dropped")
+	decayFactor      = flag.Float64("decay", 0, "Multiply existing counts by this factor in (0,1) before each training document so older data fades (0 disables)")
+	hashBuckets      = flag.Int("hash-buckets", 0, "Hash features into this many buckets to bound memory (0 disables)")
+	labelOrder       = flag.String("label-order", "", "Comma-separated label order for the confusion matrix and classification report in evaluate mode; unlisted labels follow alphabetically")
+	leakageMinCount  = flag.Float64("leakage-min-count", 0, "List tokens seen at least this often that occur in only one class in evaluate mode, as possible label leakage (0 disables)")
+	mostUncertain    = flag.Int("most-uncertain", 0, "In predict-file mode, write only the N input lines with the most uncertain predictions, most uncertain first (0 disables)")
+	topK             = flag.Int("topk", 0, "Also report top-K accuracy in evaluate mode (0 disables)")
+	verbose          = flag.Bool("verbose", false, "Log training time and a one-line model summary (documents per class, vocabulary size) before the mode runs")
+	minAccuracy      = flag.Float64("min-accuracy", 0, "Exit non-zero in evaluate mode when accuracy falls below this value in [0,1] (0 disables)")
 )
 
+// jsonPrecision is the number of decimal places kept in JSON probabilities, or
+// -1 to emit them unrounded. It is only set when -precision is given explicitly
+// so JSON output keeps full precision by default.
+var jsonPrecision = -1
+
+// predictionCache serves repeated texts without rerunning the model. It is nil
+// unless -cache-size is set.
+var predictionCache *sentiment.CachedClassifier
+
 func main() {
 	flag.Parse()
+	if *configPath != "" {
+		cfg, err := loadConfigFile(*configPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := mergeConfig(flag.CommandLine, cfg); err != nil {
+			log.Fatal(err)
+		}
+	}
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "precision" {
+			jsonPrecision = *precision
+		}
+	})
+
+	if *probFormat != "float" && *probFormat != "fixed" {
+		log.Fatalf("unknown -probability-format %q (expected float|fixed)", *probFormat)
+	}
+
+	if *mode == "validate" {
+		if err := runValidateMode(*datasetPath); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if *mode == "selftest" {
+		if err := runSelfTestMode(); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
 	docs := loadDataset(*datasetPath)
 	if len(docs) == 0 {
 		log.Fatal("no training data available")
 	}
 
-	classifier := sentiment.NewNaiveBayesClassifier()
-	snapshotLoaded, err := loadSnapshotFromDisk(classifier, *loadSnapshotPath)
+	opts, err := classifierOptions()
+	if err != nil {
+		log.Fatal(err)
+	}
+	classifier := sentiment.NewNaiveBayesClassifier(opts...)
+	if *cacheSize > 0 {
+		predictionCache = sentiment.NewCachedClassifier(classifier, *cacheSize)
+	}
+	snapshotLoaded, err := loadSnapshotFromDisk(classifier, *loadSnapshotPath, explicitFlags(flag.CommandLine))
 	if err != nil {
 		log.Fatal(err)
 	}
+	if *defaultLabel != "" {
+		classifier.SetDefaultLabel(*defaultLabel)
+	}
+	if *maskTokens != "" {
+		classifier.SetMaskedTokens(strings.Split(*maskTokens, ",")...)
+	}
+	if *smoothing != "" {
+		strategy, err := sentiment.ParseSmoothingStrategy(*smoothing)
+		if err != nil {
+			log.Fatal(err)
+		}
+		classifier.SetSmoothingStrategy(strategy)
+		classifier.SetLidstoneAlpha(*smoothingAlpha)
+	}
 	shouldTrain := !snapshotLoaded || *continueTraining
+	trainDocs, err := trainingDocuments(classifier, docs, snapshotLoaded)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	switch *mode {
 	case "demo":
-		if err := runDemo(classifier, docs, shouldTrain); err != nil {
+		if err := runDemo(classifier, trainDocs, shouldTrain); err != nil {
 			log.Fatal(err)
 		}
 	case "classify":
-		if err := runClassifyMode(classifier, docs, *textInput, shouldTrain); err != nil {
+		if err := runClassifyMode(classifier, trainDocs, *textInput, shouldTrain); err != nil {
 			log.Fatal(err)
 		}
 	case "evaluate":
-		if err := runEvaluationMode(classifier, docs, *splitRatio, *randomSeed); err != nil {
+		if err := runEvaluationMode(classifier, docs, *splitRatio, *randomSeed, *minAccuracy); err != nil {
 			log.Fatal(err)
 		}
 	case "serve":
-		if err := runServerMode(classifier, docs, *port, shouldTrain); err != nil {
+		if err := runServerMode(classifier, trainDocs, *port, shouldTrain); err != nil {
+			log.Fatal(err)
+		}
+	case "predict-file":
+		if err := runPredictFileMode(classifier, trainDocs, *inputPath, *outputPath, *outputFormat, shouldTrain); err != nil {
+			log.Fatal(err)
+		}
+	case "compare":
+		if err := runCompareMode(classifier, docs, *compareSnapshot, *splitRatio, *randomSeed); err != nil {
+			log.Fatal(err)
+		}
+	case "vocab":
+		if err := runVocabMode(classifier, trainDocs, *outputPath, *vocabCounts, shouldTrain); err != nil {
 			log.Fatal(err)
 		}
 	default:
-		log.Fatalf("unknown mode %q (expected demo|classify|evaluate|serve)", *mode)
+		log.Fatalf("unknown mode %q (expected demo|classify|evaluate|serve|predict-file|validate|selftest|vocab|compare)", *mode)
 	}
 }
 
+func classifierOptions() ([]sentiment.Option, error) {
+	opts := []sentiment.Option{sentiment.PriorWeight(*priorWeight)}
+	tf, err := sentiment.ParseTermFrequencyMode(*tfMode)
+	if err != nil {
+		return nil, err
+	}
+	if tf != sentiment.TFCount {
+		opts = append(opts, sentiment.TermFrequency(tf))
+	}
+	if *sublinearTF {
+		opts = append(opts, sentiment.SublinearTF())
+	}
+	if *positionFeatures {
+		opts = append(opts, sentiment.PositionFeatures())
+	}
+	if *maxVocabSize > 0 {
+		opts = append(opts, sentiment.MaxVocabSize(*maxVocabSize))
+	}
+	if *minDocFreq > 1 {
+		opts = append(opts, sentiment.MinDocumentFrequency(*minDocFreq))
+	}
+	if *goodTuringOOV {
+		opts = append(opts, sentiment.GoodTuringOOV())
+	}
+	if *ngramMax > 1 {
+		opts = append(opts, sentiment.NGrams(*ngramMax))
+	}
+	if *maxTokenRepeats > 0 {
+		opts = append(opts, sentiment.MaxTokenRepeats(*maxTokenRepeats))
+	}
+	if *sentenceBreaks {
+		opts = append(opts, sentiment.SentenceBreaks())
+	}
+	if *coocWindow > 0 {
+		opts = append(opts, sentiment.CoOccurrence(*coocWindow))
+	}
+	if *bigramWeight != 1 {
+		opts = append(opts, sentiment.NGramWeights(map[int]float64{2: *bigramWeight}))
+	}
+	if *stripHTML {
+		opts = append(opts, sentiment.StripHTML())
+	}
+	if *splitIdents {
+		opts = append(opts, sentiment.SplitIdentifiers())
+	}
+	if *collapseRepeats > 0 {
+		opts = append(opts, sentiment.CollapseRepeatedChars(*collapseRepeats))
+	}
+	if *hashBuckets > 0 {
+		opts = append(opts, sentiment.FeatureHashing(*hashBuckets))
+	}
+	if *decayFactor > 0 {
+		opts = append(opts, sentiment.DecayFactor(*decayFactor))
+	}
+	if *tokenAllow != "" {
+		re, err := regexp.Compile(*tokenAllow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -token-allow: %w", err)
+		}
+		opts = append(opts, sentiment.TokenAllowPattern(re))
+	}
+	if *tokenDeny != "" {
+		re, err := regexp.Compile(*tokenDeny)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -token-deny: %w", err)
+		}
+		opts = append(opts, sentiment.TokenDenyPattern(re))
+	}
+	return opts, nil
+}
+
 func loadDataset(path string) []sentiment.Document {
-    docs, err := dataset.LoadCSV(path)
+    docs, err := readDataset(path)
     if err == nil {
         return docs
     }
-    log.Printf("warning: %v, falling back to built-in dataset", err)
+    log.Printf("WARNING: could not load dataset %s: %v", path, err)
+    if *fallbackDataset != "" {
+        docs, fallbackErr := readDataset(*fallbackDataset)
+        if fallbackErr == nil {
+            log.Printf("WARNING: using fallback dataset %s (%d documents)", *fallbackDataset, len(docs))
+            return docs
+        }
+        log.Printf("WARNING: could not load fallback dataset %s: %v", *fallbackDataset, fallbackErr)
+    }
+    if *strictDataset {
+        log.Fatalf("load dataset %s: %v", path, err)
+    }
+    log.Printf("WARNING: falling back to the built-in demo dataset (%d documents); pass -strict-dataset to make this fatal", len(sentiment.DefaultDataset()))
     return sentiment.DefaultDataset()
 }
 
+// readDataset loads path with the loader matching its kind: an http(s) URL,
+// a directory of per-label folders, a .jsonl file or, by default, a CSV file.
+func readDataset(path string) ([]sentiment.Document, error) {
+	if dataset.IsURL(path) {
+		return dataset.LoadURL(path, *datasetTimeout, *datasetRetries, datasetOptions()...)
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return dataset.LoadDirectory(path, datasetOptions()...)
+	}
+	if strings.HasSuffix(strings.ToLower(path), ".jsonl") {
+		return dataset.LoadJSONL(path, datasetOptions()...)
+	}
+	return dataset.LoadCSV(path, datasetOptions()...)
+}
+
+func datasetOptions() []dataset.LoadOption {
+	opts := []dataset.LoadOption{dataset.JSONFields(*jsonTextField, *jsonLabelField)}
+	if *datasetLanguage != "" {
+		opts = append(opts, dataset.FilterLanguage(*datasetLanguage))
+	}
+	return opts
+}
+
+// runValidateMode loads the dataset and reports how many rows were used and
+// why any were skipped, without training.
+func runValidateMode(path string) error {
+	docs, report, err := dataset.LoadCSVVerbose(path, datasetOptions()...)
+	fmt.Printf("Rows read: %d\n", report.Rows)
+	fmt.Printf("Documents loaded: %d\n", report.Loaded)
+	fmt.Printf("Rows skipped: %d\n", len(report.Skipped))
+	for _, skipped := range report.Skipped {
+		fmt.Printf("  line %d: %s\n", skipped.Line, skipped.Reason)
+	}
+	if err != nil {
+		return fmt.Errorf("validate dataset %s: %w", path, err)
+	}
+	counts := make(map[string]int)
+	for _, doc := range docs {
+		counts[doc.Label]++
+	}
+	labels := make([]string, 0, len(counts))
+	for label := range counts {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	fmt.Println("Label distribution:")
+	for _, label := range labels {
+		fmt.Printf("  %s: %d\n", label, counts[label])
+	}
+	return nil
+}
+
+// runSelfTestMode trains a default classifier on the built-in dataset and
+// checks that every demo sentence with an obvious sentiment gets its expected
+// label. Demo sentences without an expectation are printed as SKIP so the
+// output shows what is not checked. It ignores -dataset and the model flags
+// so the result only depends on the code.
+func runSelfTestMode() error {
+	classifier := sentiment.NewNaiveBayesClassifier()
+	classifier.TrainBatch(sentiment.DefaultDataset())
+	logModelSummary(classifier)
+
+	failures := 0
+	for _, sentence := range sentiment.DemoSentences {
+		expected, ok := sentiment.DemoExpectations[sentence]
+		label, probs := classifier.Predict(sentence)
+		if !ok {
+			fmt.Printf("SKIP %q -> %s (no expectation, p=%.*f)\n", sentence, label, *precision, probs[label])
+			continue
+		}
+		status := "PASS"
+		if label != expected {
+			status = "FAIL"
+			failures++
+		}
+		fmt.Printf("%s %q -> %s (expected %s, p=%.*f)\n", status, sentence, label, expected, *precision, probs[label])
+	}
+	if failures > 0 {
+		return fmt.Errorf("selftest: %d of %d checks failed", failures, len(sentiment.DemoExpectations))
+	}
+	fmt.Printf("selftest: all %d checks passed\n", len(sentiment.DemoExpectations))
+	return nil
+}
+
+// logModelSummary logs the document counts and vocabulary size of the model
+// on one line when -verbose is set.
+func logModelSummary(classifier *sentiment.NaiveBayesClassifier) {
+	if !*verbose {
+		return
+	}
+	snapshot := classifier.Snapshot()
+	counts := make([]string, 0, len(snapshot.ClassDocCounts))
+	for _, label := range classifier.Labels() {
+		counts = append(counts, fmt.Sprintf("%s=%g", label, snapshot.ClassDocCounts[label]))
+	}
+	log.Printf("Model: %g documents (%s), vocabulary size %d", snapshot.TotalDocs, strings.Join(counts, ", "), len(snapshot.Vocabulary))
+}
+
+// trainingDocuments returns the documents the modes train on: docs, minus
+// those a checkpoint already holds under -resume, or the -finetune-dataset
+// file, whose counts are added to the loaded snapshot's.
+func trainingDocuments(classifier *sentiment.NaiveBayesClassifier, docs []sentiment.Document, snapshotLoaded bool) ([]sentiment.Document, error) {
+	trainDocs := docs
+	if *resumeTraining {
+		if !snapshotLoaded || !*continueTraining {
+			return nil, errors.New("-resume requires -load-snapshot and -continue-training")
+		}
+		trainDocs = skipTrainedDocs(docs, classifier.TotalDocs())
+	}
+	if *finetuneDataset != "" {
+		if !snapshotLoaded || !*continueTraining {
+			return nil, errors.New("-finetune-dataset requires -load-snapshot and -continue-training")
+		}
+		finetune, err := readDataset(*finetuneDataset)
+		if err != nil {
+			return nil, fmt.Errorf("load finetune dataset %s: %w", *finetuneDataset, err)
+		}
+		trainDocs = finetune
+	}
+	return trainDocs, nil
+}
+
+// skipTrainedDocs drops the documents a checkpoint already holds. It relies on
+// the dataset being read in the same order and on every document counting
+// once, so it does not apply to models trained with -decay or /train feedback.
+func skipTrainedDocs(docs []sentiment.Document, trained float64) []sentiment.Document {
+	n := int(math.Round(trained))
+	if n > len(docs) {
+		n = len(docs)
+	}
+	log.Printf("Resuming training after %d of %d documents", n, len(docs))
+	return docs[n:]
+}
+
+// trainClassifier trains on docs, stopping early when -train-timeout is set
+// and runs out, and writing -checkpoint-every snapshots along the way. A
+// partially trained model is logged but still used. -quantize is applied
+// afterwards.
+func trainClassifier(classifier *sentiment.NaiveBayesClassifier, docs []sentiment.Document) {
+	if *checkpointEvery <= 0 && *trainTimeout <= 0 {
+		logTrainStats(classifier.TrainBatchTimed(docs))
+	} else {
+		start := time.Now()
+		n := trainBounded(classifier, docs)
+		logTrainStats(sentiment.TrainStats{Documents: n, Duration: time.Since(start)})
+	}
+	if *quantizeScale > 1 {
+		classifier.Quantize(*quantizeScale)
+	}
+}
+
+// logTrainStats logs the training time and throughput under -verbose.
+func logTrainStats(stats sentiment.TrainStats) {
+	if !*verbose {
+		return
+	}
+	log.Printf("Trained %d documents in %s (%.0f docs/s)", stats.Documents, stats.Duration, stats.DocsPerSecond())
+}
+
+// trainBounded trains under -train-timeout and -checkpoint-every and returns
+// how many documents were trained.
+func trainBounded(classifier *sentiment.NaiveBayesClassifier, docs []sentiment.Document) int {
+	ctx := context.Background()
+	if *trainTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *trainTimeout)
+		defer cancel()
+	}
+	checkpoint := func(trained int) error {
+		if err := writeSnapshot(classifier, *checkpointPath); err != nil {
+			return fmt.Errorf("checkpoint: %w", err)
+		}
+		log.Printf("Checkpoint after %d of %d documents saved to %s", trained, len(docs), *checkpointPath)
+		return nil
+	}
+	n, err := classifier.TrainBatchCheckpointed(ctx, docs, *checkpointEvery, checkpoint)
+	if err != nil {
+		log.Printf("WARNING: training stopped after %d of %d documents: %v", n, len(docs), err)
+	}
+	return n
+}
+
 func runDemo(classifier *sentiment.NaiveBayesClassifier, docs []sentiment.Document, train bool) error {
 	if train {
-		classifier.TrainBatch(docs)
+		trainClassifier(classifier, docs)
 	}
+	logModelSummary(classifier)
 	if err := saveSnapshotIfNeeded(classifier); err != nil {
 		return err
 	}
 	fmt.Println("Sample predictions:")
 	for _, sentence := range sentiment.DemoSentences {
-		label, probs := classifier.Predict(sentence)
+		label, probs := predictText(classifier, sentence)
 		fmt.Printf("%q -> %s\n", sentence, label)
 		printProbabilities(probs)
 	}
@@ -94,51 +521,191 @@ func runClassifyMode(classifier *sentiment.NaiveBayesClassifier, docs []sentimen
 		return errors.New("-text is required in classify mode")
 	}
 	if train {
-		classifier.TrainBatch(docs)
+		trainClassifier(classifier, docs)
 	}
+	logModelSummary(classifier)
 	if err := saveSnapshotIfNeeded(classifier); err != nil {
 		return err
 	}
-	label, probs := classifier.Predict(text)
+	if len(classifier.Tokenize(text)) == 0 {
+		log.Printf("WARNING: %s", zeroTokensWarning)
+	}
+	label, probs := predictText(classifier, text)
 	fmt.Printf("Input: %q\n", text)
 	fmt.Printf("Predicted sentiment: %s\n", label)
 	printProbabilities(probs)
 	return nil
 }
 
-func runEvaluationMode(classifier *sentiment.NaiveBayesClassifier, docs []sentiment.Document, split float64, seed int64) error {
-    train, test := dataset.SplitDataset(docs, split, seed)
-    if len(test) == 0 {
-        return errors.New("not enough samples to create a test set; provide a larger dataset")
+// evaluationSplit returns the train and test documents for evaluate and
+// compare modes: all of docs and the -test-dataset file when one is given,
+// otherwise a random (or with -stratified, per-class) split of docs.
+func evaluationSplit(docs []sentiment.Document, split float64, seed int64) (train, test []sentiment.Document, err error) {
+	if *testDataset != "" {
+		test, err = readDataset(*testDataset)
+		if err != nil {
+			return nil, nil, fmt.Errorf("load test dataset %s: %w", *testDataset, err)
+		}
+		return docs, test, nil
+	}
+	splitFn := dataset.SplitDataset
+	if *stratified {
+		splitFn = dataset.StratifiedSplit
+	}
+	train, test = splitFn(docs, split, seed)
+	if len(test) == 0 {
+		return nil, nil, errors.New("not enough samples to create a test set; provide a larger dataset")
+	}
+	return train, test, nil
+}
+
+func runEvaluationMode(classifier *sentiment.NaiveBayesClassifier, docs []sentiment.Document, split float64, seed int64, minAcc float64) error {
+    if labels := distinctLabels(docs); len(labels) < 2 {
+        return fmt.Errorf("dataset has a single label %q; evaluation needs at least two classes to be meaningful", strings.Join(labels, ""))
+    }
+    train, test, err := evaluationSplit(docs, split, seed)
+    if err != nil {
+        return err
     }
     classifier.Reset()
-    classifier.TrainBatch(train)
-    metrics := sentiment.Evaluate(classifier, test)
+    logTrainStats(classifier.TrainBatchTimed(train))
+    logModelSummary(classifier)
+    weights, err := parseClassWeights(*classWeights)
+    if err != nil {
+        return err
+    }
+    detailed := sentiment.EvaluateDetailedWeighted(classifier, test, weights)
+    metrics := detailed.Metrics
 
     fmt.Printf("Train set size: %d\n", len(train))
     fmt.Printf("Test set size: %d\n", len(test))
     fmt.Printf("Accuracy: %.2f%% (%d/%d)\n", metrics.Accuracy()*100, metrics.Correct, metrics.Total)
+    if len(weights) > 0 {
+        fmt.Printf("Weighted accuracy: %.2f%%\n", metrics.WeightedAccuracy()*100)
+    }
+    if *topK > 0 {
+        fmt.Printf("Top-%d accuracy: %.2f%%\n", *topK, sentiment.TopKAccuracy(classifier, test, *topK)*100)
+    }
+    fmt.Printf("Perplexity: %.2f\n", sentiment.Perplexity(classifier, test))
+    fmt.Printf("Log loss: %.4f\n", sentiment.LogLoss(classifier, test))
+    fmt.Printf("Class separation (mean KL, bits): %.4f\n", classifier.ClassSeparation())
     fmt.Println("Confusion matrix (actual -> predicted counts):")
-    printConfusion(metrics.Confusion)
+    order := parseLabelOrder(*labelOrder)
+    printConfusion(metrics.Confusion, order)
+    printConfidenceHistogram(metrics.ConfidenceHistogram(10))
+    fmt.Printf("Expected calibration error: %.4f\n", metrics.ExpectedCalibrationError(10))
+    fmt.Println("Classification report:")
+    fmt.Print(sentiment.ClassificationReportOrdered(metrics, order))
+    if *showErrors > 0 {
+        printConfusionExamples(sentiment.ExplainConfusion(detailed, *showErrors))
+    }
+    if *leakageMinCount > 0 {
+        printLeakageSuspects(classifier.LeakageSuspects(*leakageMinCount, 0))
+    }
+    if minAcc > 0 && metrics.Accuracy() < minAcc {
+        log.Printf("FAIL: accuracy %.2f%% is below the required minimum of %.2f%%", metrics.Accuracy()*100, minAcc*100)
+        os.Exit(1)
+    }
     return nil
 }
 
+// distinctLabels returns the sorted unique labels of docs.
+func distinctLabels(docs []sentiment.Document) []string {
+	seen := make(map[string]struct{})
+	var labels []string
+	for _, doc := range docs {
+		if _, ok := seen[doc.Label]; !ok {
+			seen[doc.Label] = struct{}{}
+			labels = append(labels, doc.Label)
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
 func runServerMode(classifier *sentiment.NaiveBayesClassifier, docs []sentiment.Document, port int, train bool) error {
 	if train {
-		classifier.TrainBatch(docs)
+		trainClassifier(classifier, docs)
 	}
+	logModelSummary(classifier)
 	if err := saveSnapshotIfNeeded(classifier); err != nil {
 		return err
 	}
+	ensemble, err := buildEnsemble(classifier, *ensembleSnaps, *ensembleMode)
+	if err != nil {
+		return err
+	}
+	var predLog *predictionLog
+	if *predictionsLog != "" {
+		predLog, err = openPredictionLog(*predictionsLog, time.Second)
+		if err != nil {
+			return err
+		}
+		defer predLog.Close()
+	}
 	srv := &http.Server{
-		Addr:    fmt.Sprintf(":%d", port),
-		Handler: buildRouter(classifier),
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           buildRouter(classifier, ensemble, predLog),
+		ReadHeaderTimeout: *readTimeout,
+		ReadTimeout:       *readTimeout,
+		WriteTimeout:      *writeTimeout,
+		IdleTimeout:       *idleTimeout,
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		return errors.New("-tls-cert and -tls-key must be provided together")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+	scheme := "http"
+	if *tlsCert != "" {
+		scheme = "https"
+	}
+	log.Printf("Serving sentiment API on %s://localhost:%d/classify", scheme, port)
+	return serveUntilDone(ctx, srv, ln, *tlsCert, *tlsKey)
+}
+
+// shutdownTimeout bounds how long serve mode waits for in-flight requests
+// after SIGINT or SIGTERM.
+const shutdownTimeout = 10 * time.Second
+
+// serveUntilDone serves srv on ln until ctx is done, then shuts the server
+// down gracefully and returns nil, so deferred cleanup such as flushing the
+// predictions log runs before the process exits.
+func serveUntilDone(ctx context.Context, srv *http.Server, ln net.Listener, certFile, keyFile string) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- serveListener(srv, ln, certFile, keyFile)
+	}()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	log.Printf("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shutdown: %w", err)
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
-	log.Printf("Serving sentiment API on http://localhost:%d/classify", port)
-	return srv.ListenAndServe()
+	return nil
 }
 
-func buildRouter(classifier *sentiment.NaiveBayesClassifier) http.Handler {
+// serveListener serves srv on ln, over TLS when certFile and keyFile are set.
+func serveListener(srv *http.Server, ln net.Listener, certFile, keyFile string) error {
+	if certFile != "" {
+		return srv.ServeTLS(ln, certFile, keyFile)
+	}
+	return srv.Serve(ln)
+}
+
+func buildRouter(classifier *sentiment.NaiveBayesClassifier, ensemble *sentiment.Ensemble, predLog *predictionLog) http.Handler {
     mux := http.NewServeMux()
     mux.HandleFunc("/classify", func(w http.ResponseWriter, r *http.Request) {
         if r.Method != http.MethodPost {
@@ -154,12 +721,100 @@ func buildRouter(classifier *sentiment.NaiveBayesClassifier) http.Handler {
             http.Error(w, "text is required", http.StatusBadRequest)
             return
         }
-        label, probs := classifier.Predict(req.Text)
-        resp := classifyResponse{Label: label, Probabilities: probs}
+        zeroTokens := len(classifier.Tokenize(req.Text)) == 0
+        if zeroTokens && *rejectEmpty {
+            http.Error(w, zeroTokensWarning, http.StatusUnprocessableEntity)
+            return
+        }
+        label, probs := predictText(classifier, req.Text)
+        requestID := requestIDFrom(r.Context())
+        predLog.Record(requestID, req.Text, label, topProbability(probs))
+        resp := classifyResponse{Label: label, Probabilities: roundProbabilities(filterProbabilities(probs, req.Classes)), RequestID: requestID}
+        if zeroTokens {
+            resp.Warning = zeroTokensWarning
+        }
+        if r.URL.Query().Get("sorted") == "true" {
+            resp.Ranked = rankProbabilities(resp.Probabilities)
+        }
+        if r.URL.Query().Get("margin") == "true" {
+            resp.RunnerUp, resp.Margin = runnerUpMargin(probs)
+        }
+        if r.URL.Query().Get("entropy") == "true" {
+            entropy := sentiment.PredictionEntropy(probs)
+            resp.Entropy = &entropy
+        }
+        if r.URL.Query().Get("tokens") == "true" {
+            resp.Tokens = classifier.Tokenize(req.Text)
+        }
         w.Header().Set("Content-Type", "application/json")
         json.NewEncoder(w).Encode(resp)
     })
-    return mux
+    mux.HandleFunc("/info", func(w http.ResponseWriter, r *http.Request) {
+        snapshot := classifier.Snapshot()
+        resp := infoResponse{
+            TotalDocs:      snapshot.TotalDocs,
+            ClassDocCounts: snapshot.ClassDocCounts,
+            VocabularySize: len(snapshot.Vocabulary),
+            Smoothing:      string(snapshot.Smoothing),
+            Fingerprint:    classifier.Fingerprint(),
+            Memory:         memoryInfo(classifier.MemoryStats()),
+        }
+        w.Header().Set("Content-Type", "application/json")
+        json.NewEncoder(w).Encode(resp)
+    })
+    mux.HandleFunc("/batch", batchHandler(classifier, *batchWorkers, *batchTimeout))
+    mux.HandleFunc("/stream", streamHandler(classifier))
+    if ensemble != nil {
+        mux.HandleFunc("/compare", compareHandler(ensemble))
+    }
+    if *feedbackWeight > 0 {
+        mux.HandleFunc("/train", trainHandler(classifier, *feedbackWeight))
+        mux.HandleFunc("/correct", correctHandler(classifier, *feedbackWeight))
+    }
+    mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+        if classifier.TotalDocs() == 0 {
+            http.Error(w, "model not trained", http.StatusServiceUnavailable)
+            return
+        }
+        if err := checkCanary(classifier, *canaryText); err != nil {
+            http.Error(w, err.Error(), http.StatusServiceUnavailable)
+            return
+        }
+        fmt.Fprintln(w, "ok")
+    })
+    var handler http.Handler = withModelLock(mux)
+    if *gzipResponses {
+        handler = withGzip(handler, *gzipMinSize)
+    }
+    return withRequestID(handler)
+}
+
+// checkCanary runs a prediction on a fixed sentence and verifies the result is
+// usable, catching corrupt models that would otherwise pass a count check.
+func checkCanary(classifier *sentiment.NaiveBayesClassifier, text string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("canary prediction panicked: %v", r)
+		}
+	}()
+	label, probs := classifier.Predict(text)
+	if label == "" {
+		return errors.New("canary prediction returned an empty label")
+	}
+	if len(probs) == 0 {
+		return errors.New("canary prediction returned no probabilities")
+	}
+	var sum float64
+	for class, p := range probs {
+		if math.IsNaN(p) || p < 0 || p > 1 {
+			return fmt.Errorf("canary prediction returned invalid probability %v for %q", p, class)
+		}
+		sum += p
+	}
+	if math.Abs(sum-1) > 1e-6 {
+		return fmt.Errorf("canary probabilities sum to %v", sum)
+	}
+	return nil
 }
 
 func printProbabilities(probs map[string]float64) {
@@ -173,23 +828,182 @@ func printProbabilities(probs map[string]float64) {
     }
     sort.Strings(classes)
     for _, class := range classes {
-        fmt.Printf("  %s: %.2f\n", class, probs[class])
+        fmt.Printf("  %s: %.*f\n", class, *precision, probs[class])
     }
 }
 
-func printConfusion(confusion map[string]map[string]int) {
+// predictText classifies text, applying the -min-known-fraction and
+// -neutral-band policies. Results come from predictionCache when -cache-size
+// is set and -min-known-fraction is not; every caller passes the model the
+// cache was built for.
+func predictText(classifier *sentiment.NaiveBayesClassifier, text string) (string, map[string]float64) {
+	return predictTextPolicy(classifier, text, defaultPolicy())
+}
+
+// predictPolicy holds the settings that relabel a prediction after the model
+// has scored it.
+type predictPolicy struct {
+	NeutralBand      float64
+	MinKnownFraction float64
+	AbstainLabel     string
+}
+
+// defaultPolicy returns the policy configured by the command-line flags.
+func defaultPolicy() predictPolicy {
+	return predictPolicy{
+		NeutralBand:      *neutralBand,
+		MinKnownFraction: *minKnownFraction,
+		AbstainLabel:     *abstainLabel,
+	}
+}
+
+// predictTextPolicy behaves like predictText with policy in place of the
+// flag settings.
+func predictTextPolicy(classifier *sentiment.NaiveBayesClassifier, text string, policy predictPolicy) (string, map[string]float64) {
+	band := sentiment.NewNeutralBand(classifier, policy.NeutralBand)
+	if policy.MinKnownFraction > 0 {
+		abstain := &sentiment.Abstain{Model: classifier, MinKnownFraction: policy.MinKnownFraction, Label: policy.AbstainLabel}
+		prediction := classifier.PredictDetailed(text)
+		if abstain.Abstains(prediction) {
+			return abstain.Label, prediction.Probabilities
+		}
+		return band.Apply(prediction.Label, prediction.Probabilities), prediction.Probabilities
+	}
+	if predictionCache == nil {
+		return band.Predict(text)
+	}
+	label, probs := predictionCache.Predict(text)
+	return band.Apply(label, probs), probs
+}
+
+// topProbability returns the largest class probability, which is the
+// confidence of the prediction even when a policy relabels it.
+func topProbability(probs map[string]float64) float64 {
+	var top float64
+	for _, p := range probs {
+		if p > top {
+			top = p
+		}
+	}
+	return top
+}
+
+// filterProbabilities keeps only the requested classes. The values are the
+// model's probabilities over all classes and are not renormalized, so they
+// stay comparable across requests with different filters. Unknown classes are
+// ignored; an empty filter returns probs unchanged.
+func filterProbabilities(probs map[string]float64, classes []string) map[string]float64 {
+	if len(classes) == 0 {
+		return probs
+	}
+	filtered := make(map[string]float64, len(classes))
+	for _, class := range classes {
+		if p, ok := probs[class]; ok {
+			filtered[class] = p
+		}
+	}
+	return filtered
+}
+
+// probabilityMap holds the class probabilities of a JSON response. With
+// -probability-format fixed it is encoded in plain decimal notation, never
+// with an exponent, for clients that mishandle values such as 5e-17.
+type probabilityMap map[string]float64
+
+func (p probabilityMap) MarshalJSON() ([]byte, error) {
+	if *probFormat != "fixed" {
+		return json.Marshal(map[string]float64(p))
+	}
+	classes := make([]string, 0, len(p))
+	for class := range p {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	buf := []byte{'{'}
+	for i, class := range classes {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		key, err := json.Marshal(class)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, key...)
+		buf = append(buf, ':')
+		buf = strconv.AppendFloat(buf, p[class], 'f', jsonPrecision, 64)
+	}
+	return append(buf, '}'), nil
+}
+
+// probabilityValue is a single probability encoded like probabilityMap values.
+type probabilityValue float64
+
+func (p probabilityValue) MarshalJSON() ([]byte, error) {
+	if *probFormat != "fixed" {
+		return json.Marshal(float64(p))
+	}
+	return strconv.AppendFloat(nil, float64(p), 'f', jsonPrecision, 64), nil
+}
+
+type rankedProbability struct {
+	Label       string           `json:"label"`
+	Probability probabilityValue `json:"probability"`
+}
+
+// runnerUpMargin returns the second most probable class and its probability
+// gap to the winner, rounded like the other JSON probabilities.
+func runnerUpMargin(probs map[string]float64) (string, *probabilityValue) {
+	ranked := sentiment.RankProbabilities(probs)
+	if len(ranked) == 0 {
+		return "", nil
+	}
+	margin := ranked[0].Probability
+	runnerUp := ""
+	if len(ranked) > 1 {
+		runnerUp = ranked[1].Label
+		margin -= ranked[1].Probability
+	}
+	rounded := probabilityValue(roundProbabilities(map[string]float64{"": margin})[""])
+	return runnerUp, &rounded
+}
+
+// rankProbabilities lists probs from most to least probable.
+func rankProbabilities(probs map[string]float64) []rankedProbability {
+	ranked := sentiment.RankProbabilities(probs)
+	out := make([]rankedProbability, len(ranked))
+	for i, entry := range ranked {
+		out[i] = rankedProbability{Label: entry.Label, Probability: probabilityValue(entry.Probability)}
+	}
+	return out
+}
+
+// roundProbabilities returns probs rounded to jsonPrecision decimal places, or
+// probs unchanged when no JSON precision is configured.
+func roundProbabilities(probs map[string]float64) probabilityMap {
+	if jsonPrecision < 0 {
+		return probs
+	}
+	scale := math.Pow(10, float64(jsonPrecision))
+	rounded := make(map[string]float64, len(probs))
+	for class, p := range probs {
+		rounded[class] = math.Round(p*scale) / scale
+	}
+	return rounded
+}
+
+func printConfusion(confusion map[string]map[string]int, order []string) {
     actualLabels := make([]string, 0, len(confusion))
     for label := range confusion {
         actualLabels = append(actualLabels, label)
     }
-    sort.Strings(actualLabels)
+    actualLabels = sentiment.OrderLabels(actualLabels, order)
     for _, actual := range actualLabels {
         predicted := confusion[actual]
         predictedLabels := make([]string, 0, len(predicted))
         for label := range predicted {
             predictedLabels = append(predictedLabels, label)
         }
-        sort.Strings(predictedLabels)
+        predictedLabels = sentiment.OrderLabels(predictedLabels, order)
         fmt.Printf("  %s ->", actual)
         for _, label := range predictedLabels {
             fmt.Printf(" %s:%d", label, predicted[label])
@@ -198,20 +1012,129 @@ func printConfusion(confusion map[string]map[string]int) {
     }
 }
 
+// printConfidenceHistogram prints the non-empty confidence buckets with the
+// accuracy observed inside each, a text form of a reliability diagram.
+func printConfidenceHistogram(buckets []sentiment.ConfidenceBucket) {
+	fmt.Println("Confidence histogram (confidence range: count, accuracy, mean confidence):")
+	for _, bucket := range buckets {
+		if bucket.Count == 0 {
+			continue
+		}
+		fmt.Printf("  %.1f-%.1f: %d, %.2f%%, %.2f\n", bucket.Lower, bucket.Upper, bucket.Count, bucket.Accuracy()*100, bucket.MeanConfidence)
+	}
+}
+
+// parseLabelOrder splits a comma-separated label list, normalizing each label
+// the way the dataset loaders do.
+func parseLabelOrder(spec string) []string {
+	var order []string
+	for _, label := range strings.Split(spec, ",") {
+		if label = sentiment.NormalizeLabel(label); label != "" {
+			order = append(order, label)
+		}
+	}
+	return order
+}
+
+// parseClassWeights parses "label=weight,label=weight" into a weight map.
+func parseClassWeights(spec string) (map[string]float64, error) {
+	if spec == "" {
+		return nil, nil
+	}
+	weights := make(map[string]float64)
+	for _, pair := range strings.Split(spec, ",") {
+		label, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid class weight %q (expected label=weight)", pair)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight for class %q: %q", label, value)
+		}
+		weights[strings.ToLower(strings.TrimSpace(label))] = weight
+	}
+	return weights, nil
+}
+
+func printConfusionExamples(cells []sentiment.ConfusionCell) {
+	if len(cells) == 0 {
+		fmt.Println("No misclassified examples.")
+		return
+	}
+	fmt.Println("Misclassified examples (actual -> predicted):")
+	for _, cell := range cells {
+		fmt.Printf("  %s -> %s (%d):\n", cell.Actual, cell.Predicted, cell.Count)
+		for i, text := range cell.Examples {
+			if id := cell.ExampleIDs[i]; id != "" {
+				fmt.Printf("    - [%s] %q\n", id, text)
+				continue
+			}
+			fmt.Printf("    - %q\n", text)
+		}
+	}
+}
+
+// printLeakageSuspects lists the tokens that only ever occurred in one class.
+func printLeakageSuspects(suspects []sentiment.LeakageSuspect) {
+	if len(suspects) == 0 {
+		fmt.Println("No leakage suspects.")
+		return
+	}
+	fmt.Println("Leakage suspects (token -> only class, count):")
+	for _, s := range suspects {
+		fmt.Printf("  %s -> %s (%g)\n", s.Token, s.Class, s.Count)
+	}
+}
+
 type classifyRequest struct {
     Text string `json:"text"`
+    // Classes optionally restricts the returned probabilities to these labels.
+    Classes []string `json:"classes,omitempty"`
 }
 
 type classifyResponse struct {
-    Label         string             `json:"label"`
-    Probabilities map[string]float64 `json:"probabilities"`
+    Label         string              `json:"label"`
+    Probabilities probabilityMap      `json:"probabilities"`
+    RequestID     string              `json:"request_id,omitempty"`
+    Tokens        []string            `json:"tokens,omitempty"`
+    Warning       string              `json:"warning,omitempty"`
+    Ranked        []rankedProbability `json:"ranked,omitempty"`
+    RunnerUp      string              `json:"runner_up,omitempty"`
+    Margin        *probabilityValue   `json:"margin,omitempty"`
+    Entropy       *float64            `json:"entropy,omitempty"`
+}
+
+// zeroTokensWarning explains why a prediction for text without any tokens
+// should not be trusted.
+const zeroTokensWarning = "input produced no tokens; the prediction reflects only the class prior"
+
+type memoryInfo struct {
+	Classes           int `json:"classes"`
+	VocabularyEntries int `json:"vocabulary_entries"`
+	WordCountEntries  int `json:"word_count_entries"`
+	VocabularyBytes   int `json:"vocabulary_bytes"`
+	WordCountBytes    int `json:"word_count_bytes"`
+	TotalBytes        int `json:"total_bytes"`
+}
+
+type infoResponse struct {
+	TotalDocs      float64            `json:"total_docs"`
+	ClassDocCounts map[string]float64 `json:"class_doc_counts"`
+	VocabularySize int                `json:"vocabulary_size"`
+	Smoothing      string             `json:"smoothing"`
+	Fingerprint    string             `json:"fingerprint"`
+	Memory         memoryInfo         `json:"memory"`
 }
 
-func loadSnapshotFromDisk(classifier *sentiment.NaiveBayesClassifier, path string) (bool, error) {
+// loadSnapshotFromDisk loads the snapshot at path, which may also be "-" for
+// stdin or an http(s) URL fetched within -dataset-timeout. The options saved
+// in the snapshot replace the classifier's, except those whose flags are
+// named in explicit: an option set on the command line keeps its flag value.
+func loadSnapshotFromDisk(classifier *sentiment.NaiveBayesClassifier, path string, explicit map[string]bool) (bool, error) {
 	if path == "" {
 		return false, nil
 	}
-	data, err := os.ReadFile(path)
+	data, err := readSnapshotData(path)
 	if err != nil {
 		return false, fmt.Errorf("load snapshot: %w", err)
 	}
@@ -219,24 +1142,60 @@ func loadSnapshotFromDisk(classifier *sentiment.NaiveBayesClassifier, path strin
 	if err := json.Unmarshal(data, &snapshot); err != nil {
 		return false, fmt.Errorf("decode snapshot: %w", err)
 	}
+	if snapshot.Options != nil {
+		overrideSnapshotOptions(snapshot.Options, explicit)
+	}
 	classifier.LoadSnapshot(snapshot)
 	log.Printf("Loaded snapshot from %s", path)
 	return true, nil
 }
 
+// readSnapshotData returns the raw snapshot JSON from a file, stdin or a URL.
+func readSnapshotData(path string) ([]byte, error) {
+	switch {
+	case path == "-":
+		return io.ReadAll(os.Stdin)
+	case dataset.IsURL(path):
+		client := &http.Client{Timeout: *datasetTimeout}
+		resp, err := client.Get(path)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetch %s: unexpected status %s", path, resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	default:
+		return os.ReadFile(path)
+	}
+}
+
 func saveSnapshotIfNeeded(classifier *sentiment.NaiveBayesClassifier) error {
 	if *saveSnapshotPath == "" {
 		return nil
 	}
-	snapshot := classifier.Snapshot()
-	payload, err := json.MarshalIndent(snapshot, "", "  ")
+	if err := writeSnapshot(classifier, *saveSnapshotPath); err != nil {
+		return err
+	}
+	log.Printf("Snapshot saved to %s", *saveSnapshotPath)
+	return nil
+}
+
+// writeSnapshot writes the classifier's snapshot to path through a temporary
+// file and a rename, so a crash mid-write never leaves a truncated snapshot.
+func writeSnapshot(classifier *sentiment.NaiveBayesClassifier, path string) error {
+	payload, err := json.MarshalIndent(classifier.Snapshot(), "", "  ")
 	if err != nil {
 		return fmt.Errorf("encode snapshot: %w", err)
 	}
-	if err := os.WriteFile(*saveSnapshotPath, payload, 0o644); err != nil {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, payload, 0o644); err != nil {
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
 		return fmt.Errorf("write snapshot: %w", err)
 	}
-	log.Printf("Snapshot saved to %s", *saveSnapshotPath)
 	return nil
 }
 
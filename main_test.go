@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"sentimentbayes/sentiment"
+)
+
+func TestMain(m *testing.M) {
+	log.SetOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// captureLog collects log output for the rest of the test.
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(io.Discard) })
+	return &buf
+}
+
+// serve sends a request with an optional JSON body to handler and returns
+// the recorded response.
+func serve(t *testing.T, handler http.Handler, method, target string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, target, reader))
+	return rec
+}
+
+// decodeBody decodes a JSON response body into v.
+func decodeBody(t *testing.T, rec *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %q", rec.Code, rec.Body.String())
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+}
+
+func TestInfoFingerprint(t *testing.T) {
+	classifier := trainedClassifier()
+	router := buildRouter(classifier, nil, nil)
+
+	var info infoResponse
+	decodeBody(t, serve(t, router, http.MethodGet, "/info", nil), &info)
+	if info.Fingerprint != classifier.Fingerprint() {
+		t.Errorf("/info fingerprint = %q, want %q", info.Fingerprint, classifier.Fingerprint())
+	}
+
+	classifier.Train("brand new words", "positive")
+	var updated infoResponse
+	decodeBody(t, serve(t, router, http.MethodGet, "/info", nil), &updated)
+	if updated.Fingerprint == info.Fingerprint {
+		t.Error("/info fingerprint did not change after training")
+	}
+}
+
+func TestInfoMemory(t *testing.T) {
+	classifier := trainedClassifier()
+	var info infoResponse
+	decodeBody(t, serve(t, buildRouter(classifier, nil, nil), http.MethodGet, "/info", nil), &info)
+	if want := memoryInfo(classifier.MemoryStats()); info.Memory != want {
+		t.Errorf("/info memory = %+v, want %+v", info.Memory, want)
+	}
+}
+
+func TestParseLabelOrder(t *testing.T) {
+	got := parseLabelOrder(" Negative, neutral,,POSITIVE ")
+	if want := []string{"negative", "neutral", "positive"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLabelOrder = %v, want %v", got, want)
+	}
+	if got := parseLabelOrder(""); got != nil {
+		t.Errorf("parseLabelOrder(\"\") = %v, want nil", got)
+	}
+}
+
+func TestProbabilityFormatFixed(t *testing.T) {
+	probs := probabilityMap{"positive": 5e-17, "negative": 1 - 5e-17}
+
+	payload, err := json.Marshal(probs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(payload, []byte("5e-17")) {
+		t.Errorf("default encoding = %s, want the float form 5e-17", payload)
+	}
+
+	setFlag(t, probFormat, "fixed")
+	payload, err = json.Marshal(probs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"negative":1,"positive":0.00000000000000005}`
+	if string(payload) != want {
+		t.Errorf("fixed encoding = %s, want %s", payload, want)
+	}
+	var decoded map[string]float64
+	if err := json.Unmarshal(payload, &decoded); err != nil || decoded["positive"] != 5e-17 {
+		t.Errorf("fixed encoding decodes to %v (%v), want positive 5e-17", decoded, err)
+	}
+}
+
+func TestClassifyZeroTokens(t *testing.T) {
+	router := buildRouter(trainedClassifier(), nil, nil)
+
+	var resp classifyResponse
+	decodeBody(t, serve(t, router, http.MethodPost, "/classify", classifyRequest{Text: "???"}), &resp)
+	if resp.Warning != zeroTokensWarning {
+		t.Errorf("warning = %q, want %q", resp.Warning, zeroTokensWarning)
+	}
+	var ok classifyResponse
+	decodeBody(t, serve(t, router, http.MethodPost, "/classify", classifyRequest{Text: "great"}), &ok)
+	if ok.Warning != "" {
+		t.Errorf("warning for a normal text = %q, want none", ok.Warning)
+	}
+
+	setFlag(t, rejectEmpty, true)
+	if rec := serve(t, router, http.MethodPost, "/classify", classifyRequest{Text: "???"}); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("with -reject-empty status = %d, want 422", rec.Code)
+	}
+}
+
+func TestLogModelSummary(t *testing.T) {
+	classifier := sentiment.NewNaiveBayesClassifier()
+	classifier.Train("great phone", "positive")
+	classifier.Train("great camera", "positive")
+	classifier.Train("awful", "negative")
+
+	logs := captureLog(t)
+	logModelSummary(classifier)
+	if logs.Len() != 0 {
+		t.Errorf("summary printed without -verbose: %q", logs.String())
+	}
+
+	setFlag(t, verbose, true)
+	logModelSummary(classifier)
+	want := "Model: 3 documents (negative=1, positive=2), vocabulary size 4\n"
+	if !strings.HasSuffix(logs.String(), want) {
+		t.Errorf("summary = %q, want it to end with %q", logs.String(), want)
+	}
+}
+
+func TestClassifySorted(t *testing.T) {
+	classifier := sentiment.NewNaiveBayesClassifier()
+	classifier.Train("great fun", "positive")
+	classifier.Train("fine okay", "neutral")
+	classifier.Train("awful boring", "negative")
+	router := buildRouter(classifier, nil, nil)
+
+	var resp classifyResponse
+	decodeBody(t, serve(t, router, http.MethodPost, "/classify?sorted=true", classifyRequest{Text: "great fun but okay"}), &resp)
+	if len(resp.Ranked) != 3 {
+		t.Fatalf("ranked = %+v, want all 3 classes", resp.Ranked)
+	}
+	if resp.Ranked[0].Label != resp.Label {
+		t.Errorf("first ranked label = %s, want the predicted %s", resp.Ranked[0].Label, resp.Label)
+	}
+	for i, entry := range resp.Ranked {
+		if float64(entry.Probability) != resp.Probabilities[entry.Label] {
+			t.Errorf("ranked %s = %v, want the map value %v", entry.Label, entry.Probability, resp.Probabilities[entry.Label])
+		}
+		if i > 0 && entry.Probability > resp.Ranked[i-1].Probability {
+			t.Errorf("ranked is not descending: %+v", resp.Ranked)
+		}
+	}
+
+	var plain classifyResponse
+	decodeBody(t, serve(t, router, http.MethodPost, "/classify", classifyRequest{Text: "great fun but okay"}), &plain)
+	if plain.Ranked != nil {
+		t.Errorf("ranked without ?sorted=true = %+v, want it omitted", plain.Ranked)
+	}
+}
+
+func TestClassifyMargin(t *testing.T) {
+	classifier := sentiment.NewNaiveBayesClassifier()
+	classifier.Train("good good great", "positive")
+	classifier.Train("bad good", "negative")
+	router := buildRouter(classifier, nil, nil)
+
+	var resp classifyResponse
+	decodeBody(t, serve(t, router, http.MethodPost, "/classify?margin=true", classifyRequest{Text: "great"}), &resp)
+	if resp.RunnerUp != "negative" || resp.Margin == nil || math.Abs(float64(*resp.Margin)-0.25) > 1e-9 {
+		t.Errorf("runner-up %q, margin %v; want negative and 0.25", resp.RunnerUp, resp.Margin)
+	}
+
+	var plain classifyResponse
+	decodeBody(t, serve(t, router, http.MethodPost, "/classify", classifyRequest{Text: "great"}), &plain)
+	if plain.RunnerUp != "" || plain.Margin != nil {
+		t.Errorf("without ?margin=true: runner-up %q, margin %v; want them omitted", plain.RunnerUp, plain.Margin)
+	}
+}
+
+func TestClassifyEntropy(t *testing.T) {
+	classifier := sentiment.NewNaiveBayesClassifier()
+	classifier.Train("good good great", "positive")
+	classifier.Train("bad good", "negative")
+	router := buildRouter(classifier, nil, nil)
+
+	var resp classifyResponse
+	decodeBody(t, serve(t, router, http.MethodPost, "/classify?entropy=true", classifyRequest{Text: "great"}), &resp)
+	// Posteriors 5/8 and 3/8.
+	want := -(5.0/8*math.Log2(5.0/8) + 3.0/8*math.Log2(3.0/8))
+	if resp.Entropy == nil || math.Abs(*resp.Entropy-want) > 1e-9 {
+		t.Errorf("entropy = %v, want %v", resp.Entropy, want)
+	}
+}
+
+func TestLogTrainStats(t *testing.T) {
+	stats := sentiment.TrainStats{Documents: 50, Duration: 2 * time.Second}
+	logs := captureLog(t)
+	logTrainStats(stats)
+	if logs.Len() != 0 {
+		t.Errorf("stats printed without -verbose: %q", logs.String())
+	}
+
+	setFlag(t, verbose, true)
+	logTrainStats(stats)
+	if want := "Trained 50 documents in 2s (25 docs/s)\n"; !strings.HasSuffix(logs.String(), want) {
+		t.Errorf("stats = %q, want it to end with %q", logs.String(), want)
+	}
+}
+
+func TestRunSelfTestModeReportsSkipped(t *testing.T) {
+	var err error
+	out := captureStdout(t, func() { err = runSelfTestMode() })
+	if err != nil {
+		t.Fatalf("runSelfTestMode = %v\n%s", err, out)
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	if len(lines) != len(sentiment.DemoSentences)+1 {
+		t.Fatalf("got %d lines, want one per demo sentence plus a summary:\n%s", len(lines), out)
+	}
+	for i, sentence := range sentiment.DemoSentences {
+		want := "PASS "
+		if _, ok := sentiment.DemoExpectations[sentence]; !ok {
+			want = "SKIP "
+		}
+		if !strings.HasPrefix(lines[i], want) || !strings.Contains(lines[i], sentence) {
+			t.Errorf("line %d = %q, want a %s line for %q", i, lines[i], strings.TrimSpace(want), sentence)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const requestIDHeader = "X-Request-Id"
+
+type requestIDKey struct{}
+
+// withRequestID reads the incoming X-Request-Id header, generating one when it
+// is absent, echoes it on the response and logs it alongside each request.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		start := time.Now()
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+		log.Printf("request_id=%s method=%s path=%s duration=%s", id, r.Method, r.URL.Path, time.Since(start))
+	})
+}
+
+// requestIDFrom returns the request id stored by withRequestID, if any.
+func requestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
+
+// withGzip compresses responses of at least minSize bytes for clients that
+// send "Accept-Encoding: gzip". Smaller responses are buffered and sent as-is,
+// and Server-Sent Event streams are never compressed so every event still
+// reaches the client as soon as it is flushed.
+func withGzip(next http.Handler, minSize int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Add("Vary", "Accept-Encoding")
+		gw := &gzipResponseWriter{ResponseWriter: w, minSize: minSize}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header value allows gzip.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		name, value, _ := strings.Cut(strings.TrimSpace(params), "=")
+		if strings.TrimSpace(name) == "q" {
+			if q, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && q == 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// gzipResponseWriter holds back the start of a response until it knows
+// whether the body is large enough to be worth compressing.
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	minSize int
+
+	status      int
+	buf         []byte
+	gz          *gzip.Writer
+	passthrough bool
+}
+
+func (g *gzipResponseWriter) WriteHeader(status int) {
+	if g.gz != nil || g.passthrough {
+		g.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if g.status == 0 {
+		g.status = status
+	}
+}
+
+func (g *gzipResponseWriter) Write(p []byte) (int, error) {
+	switch {
+	case g.gz != nil:
+		return g.gz.Write(p)
+	case g.passthrough:
+		return g.ResponseWriter.Write(p)
+	}
+	h := g.Header()
+	if h.Get("Content-Encoding") != "" || strings.HasPrefix(h.Get("Content-Type"), "text/event-stream") {
+		if err := g.startPassthrough(); err != nil {
+			return 0, err
+		}
+		return g.ResponseWriter.Write(p)
+	}
+	g.buf = append(g.buf, p...)
+	if len(g.buf) < g.minSize {
+		return len(p), nil
+	}
+	h.Set("Content-Encoding", "gzip")
+	h.Del("Content-Length")
+	g.ResponseWriter.WriteHeader(g.statusCode())
+	g.gz = gzip.NewWriter(g.ResponseWriter)
+	if _, err := g.gz.Write(g.buf); err != nil {
+		return 0, err
+	}
+	g.buf = nil
+	return len(p), nil
+}
+
+// startPassthrough sends the headers and any buffered bytes uncompressed and
+// forwards all later writes directly.
+func (g *gzipResponseWriter) startPassthrough() error {
+	g.passthrough = true
+	g.ResponseWriter.WriteHeader(g.statusCode())
+	if len(g.buf) > 0 {
+		if _, err := g.ResponseWriter.Write(g.buf); err != nil {
+			return err
+		}
+		g.buf = nil
+	}
+	return nil
+}
+
+func (g *gzipResponseWriter) statusCode() int {
+	if g.status == 0 {
+		return http.StatusOK
+	}
+	return g.status
+}
+
+// Flush sends everything written so far. A response flushed before reaching
+// minSize is sent uncompressed.
+func (g *gzipResponseWriter) Flush() {
+	if g.gz != nil {
+		g.gz.Flush()
+	} else if !g.passthrough {
+		if err := g.startPassthrough(); err != nil {
+			return
+		}
+	}
+	http.NewResponseController(g.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (g *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return g.ResponseWriter
+}
+
+func (g *gzipResponseWriter) close() {
+	switch {
+	case g.gz != nil:
+		g.gz.Close()
+	case g.passthrough:
+	case g.status != 0 || len(g.buf) > 0:
+		g.startPassthrough()
+	}
+}
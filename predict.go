@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	"sentimentbayes/sentiment"
+)
+
+// runPredictFileMode classifies every non-empty line of inputPath and writes
+// one prediction per line to outputPath (stdout when empty) as CSV or JSON
+// Lines. Output paths ending in .gz are gzip-compressed. With -most-uncertain
+// only that many of the least certain lines are written, most uncertain first.
+func runPredictFileMode(classifier *sentiment.NaiveBayesClassifier, docs []sentiment.Document, inputPath, outputPath, format string, train bool) error {
+	if inputPath == "" {
+		return errors.New("-input is required in predict-file mode")
+	}
+	if train {
+		trainClassifier(classifier, docs)
+	}
+	logModelSummary(classifier)
+	if err := saveSnapshotIfNeeded(classifier); err != nil {
+		return err
+	}
+
+	in, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("open input: %w", err)
+	}
+	defer in.Close()
+
+	out, err := openPredictionOutput(outputPath)
+	if err != nil {
+		return err
+	}
+	writer, err := newPredictionWriter(out, format)
+	if err != nil {
+		out.Close()
+		return err
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	count := 0
+	var pending []string
+	write := func(text string) error {
+		label, probs := predictText(classifier, text)
+		if err := writer.Write(text, label, probs); err != nil {
+			return fmt.Errorf("write prediction: %w", err)
+		}
+		count++
+		return nil
+	}
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		if *mostUncertain > 0 {
+			pending = append(pending, text)
+			continue
+		}
+		if err := write(text); err != nil {
+			out.Close()
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		out.Close()
+		return fmt.Errorf("read input: %w", err)
+	}
+	if *mostUncertain > 0 {
+		for _, uncertain := range classifier.MostUncertain(pending, *mostUncertain) {
+			if err := write(uncertain.Text); err != nil {
+				out.Close()
+				return err
+			}
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		out.Close()
+		return fmt.Errorf("write predictions: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("close output: %w", err)
+	}
+	if outputPath != "" {
+		log.Printf("Wrote %d predictions to %s", count, outputPath)
+	}
+	return nil
+}
+
+type predictionWriter interface {
+	Write(text, label string, probs map[string]float64) error
+	Flush() error
+}
+
+func newPredictionWriter(w io.Writer, format string) (predictionWriter, error) {
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write([]string{"text", "label", "confidence"}); err != nil {
+			return nil, err
+		}
+		return &csvPredictionWriter{w: cw}, nil
+	case "jsonl":
+		bw := bufio.NewWriter(w)
+		return &jsonlPredictionWriter{w: bw, enc: json.NewEncoder(bw)}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (expected csv|jsonl)", format)
+	}
+}
+
+type csvPredictionWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvPredictionWriter) Write(text, label string, probs map[string]float64) error {
+	digits := 4
+	if jsonPrecision >= 0 {
+		digits = jsonPrecision
+	}
+	confidence := strconv.FormatFloat(topProbability(probs), 'f', digits, 64)
+	return c.w.Write([]string{text, label, confidence})
+}
+
+func (c *csvPredictionWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlPredictionWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+type jsonlPrediction struct {
+	Text          string         `json:"text"`
+	Label         string         `json:"label"`
+	Probabilities probabilityMap `json:"probabilities"`
+}
+
+func (j *jsonlPredictionWriter) Write(text, label string, probs map[string]float64) error {
+	return j.enc.Encode(jsonlPrediction{Text: text, Label: label, Probabilities: roundProbabilities(probs)})
+}
+
+func (j *jsonlPredictionWriter) Flush() error {
+	return j.w.Flush()
+}
+
+// openPredictionOutput opens path for writing, wrapping it in a gzip writer
+// when the path ends in .gz. An empty path writes to stdout.
+func openPredictionOutput(path string) (io.WriteCloser, error) {
+	if path == "" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create output: %w", err)
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return file, nil
+	}
+	return &gzipFile{Writer: gzip.NewWriter(file), file: file}, nil
+}
+
+type gzipFile struct {
+	*gzip.Writer
+	file *os.File
+}
+
+func (g *gzipFile) Close() error {
+	if err := g.Writer.Close(); err != nil {
+		g.file.Close()
+		return err
+	}
+	return g.file.Close()
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
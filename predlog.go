@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// predictionLog appends one JSON line per classification to a file so served
+// traffic can later be reviewed and relabeled. Writes are buffered, guarded by
+// a mutex so concurrent requests never interleave partial lines, and flushed
+// periodically and on Close.
+type predictionLog struct {
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+	done chan struct{}
+}
+
+type predictionLogEntry struct {
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"request_id,omitempty"`
+	Text       string    `json:"text"`
+	Label      string    `json:"label"`
+	Confidence float64   `json:"confidence"`
+}
+
+// openPredictionLog opens path for appending and starts the background flusher.
+func openPredictionLog(path string, flushEvery time.Duration) (*predictionLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open predictions log: %w", err)
+	}
+	pl := &predictionLog{file: file, w: bufio.NewWriter(file), done: make(chan struct{})}
+	go pl.flushLoop(flushEvery)
+	return pl, nil
+}
+
+// Record appends a prediction. It is a no-op on a nil log.
+func (pl *predictionLog) Record(requestID, text, label string, confidence float64) {
+	if pl == nil {
+		return
+	}
+	line, err := json.Marshal(predictionLogEntry{
+		Time:       time.Now().UTC(),
+		RequestID:  requestID,
+		Text:       text,
+		Label:      label,
+		Confidence: confidence,
+	})
+	if err != nil {
+		log.Printf("predictions log: encode entry: %v", err)
+		return
+	}
+	line = append(line, '\n')
+
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if _, err := pl.w.Write(line); err != nil {
+		log.Printf("predictions log: write: %v", err)
+	}
+}
+
+func (pl *predictionLog) flushLoop(every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			pl.mu.Lock()
+			if err := pl.w.Flush(); err != nil {
+				log.Printf("predictions log: flush: %v", err)
+			}
+			pl.mu.Unlock()
+		case <-pl.done:
+			return
+		}
+	}
+}
+
+// Close stops the flusher, flushes buffered entries and closes the file.
+func (pl *predictionLog) Close() error {
+	if pl == nil {
+		return nil
+	}
+	close(pl.done)
+	pl.mu.Lock()
+	defer pl.mu.Unlock()
+	if err := pl.w.Flush(); err != nil {
+		pl.file.Close()
+		return err
+	}
+	return pl.file.Close()
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPredictionLogRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "predictions.jsonl")
+	pl, err := openPredictionLog(path, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pl.Record("req-1", "great phone", "positive", 0.9)
+	pl.Record("", "awful screen", "negative", 0.75)
+	if err := pl.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := readPredictionLog(t, path)
+	if len(entries) != 2 {
+		t.Fatalf("log has %d entries, want 2", len(entries))
+	}
+	if got := entries[0]; got.RequestID != "req-1" || got.Text != "great phone" || got.Label != "positive" || got.Confidence != 0.9 {
+		t.Errorf("first entry = %+v", got)
+	}
+	if got := entries[1]; got.RequestID != "" || got.Label != "negative" || got.Confidence != 0.75 {
+		t.Errorf("second entry = %+v", got)
+	}
+	var nilLog *predictionLog
+	nilLog.Record("", "ignored", "positive", 1)
+}
+
+// readPredictionLog decodes every line of the predictions log at path.
+func readPredictionLog(t *testing.T, path string) []predictionLogEntry {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var entries []predictionLogEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry predictionLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("decode %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+// freePort returns a TCP port that was free a moment ago.
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestServerModeFlushesPredictionLogOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "predictions.jsonl")
+	setFlag(t, predictionsLog, path)
+	port := freePort(t)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runServerMode(trainedClassifier(), nil, port, false)
+	}()
+
+	// Retry until the server accepts connections; the signal handler is
+	// installed before it starts listening.
+	url := "http://127.0.0.1:" + strconv.Itoa(port) + "/classify"
+	var resp *http.Response
+	var err error
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		resp, err = http.Post(url, "application/json", strings.NewReader(`{"text": "great phone"}`))
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("server never came up: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("/classify status = %d", resp.StatusCode)
+	}
+
+	self, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := self.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot send SIGINT on this platform: %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("runServerMode = %v, want a clean shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down after SIGINT")
+	}
+
+	// The log flushes every second, so the entry only made it to disk if the
+	// log was closed on shutdown.
+	entries := readPredictionLog(t, path)
+	if len(entries) != 1 || entries[0].Text != "great phone" {
+		t.Errorf("predictions log = %+v, want the buffered /classify entry", entries)
+	}
+}
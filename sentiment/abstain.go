@@ -0,0 +1,35 @@
+package sentiment
+
+// Abstain wraps a classifier and reports Label instead of a class whenever
+// too little of the input is in the vocabulary for the prediction to rest on
+// evidence rather than on the class prior. Unlike NeutralBand it looks at the
+// input, not at the confidence of the result.
+type Abstain struct {
+	Model            *NaiveBayesClassifier
+	MinKnownFraction float64
+	Label            string
+}
+
+// NewAbstain returns a policy that labels predictions "unknown" when fewer
+// than minKnownFraction of the input tokens are known to model.
+func NewAbstain(model *NaiveBayesClassifier, minKnownFraction float64) *Abstain {
+	return &Abstain{Model: model, MinKnownFraction: minKnownFraction, Label: "unknown"}
+}
+
+// Predict classifies text with the wrapped model and abstains when the
+// fraction of known tokens is below MinKnownFraction. A fraction exactly at
+// the threshold is accepted. The probabilities are the wrapped model's,
+// unchanged.
+func (a *Abstain) Predict(text string) (string, map[string]float64) {
+	prediction := a.Model.PredictDetailed(text)
+	if a.Abstains(prediction) {
+		return a.Label, prediction.Probabilities
+	}
+	return prediction.Label, prediction.Probabilities
+}
+
+// Abstains reports whether the policy rejects prediction. A text without
+// tokens is rejected whenever MinKnownFraction is positive.
+func (a *Abstain) Abstains(prediction Prediction) bool {
+	return a.MinKnownFraction > 0 && prediction.KnownTokenFraction() < a.MinKnownFraction
+}
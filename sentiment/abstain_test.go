@@ -0,0 +1,70 @@
+package sentiment
+
+import "testing"
+
+func TestAbstainThresholdBoundary(t *testing.T) {
+	nb := trainTiny()
+	tests := []struct {
+		text      string
+		threshold float64
+		want      string
+	}{
+		{"good zebra", 0.5, "positive"}, // exactly at the threshold is accepted
+		{"good zebra", 0.51, "unknown"},
+		{"good great zebra", 2.0 / 3, "positive"},
+		{"good great zebra", 0.7, "unknown"},
+		{"zebra", 0.01, "unknown"},
+		{"zebra", 0, "negative"}, // a zero threshold disables the policy
+		{"", 0.01, "unknown"},    // no tokens at all
+		{"good", 1, "positive"},
+	}
+	for _, tt := range tests {
+		a := NewAbstain(nb, tt.threshold)
+		got, probs := a.Predict(tt.text)
+		if got != tt.want {
+			t.Errorf("Predict(%q) at threshold %v = %s, want %s", tt.text, tt.threshold, got, tt.want)
+		}
+		if _, want := nb.Predict(tt.text); len(probs) != len(want) {
+			t.Errorf("Predict(%q) probabilities = %v, want the model's %v", tt.text, probs, want)
+		}
+	}
+}
+
+func TestPredictDetailedKnownTokens(t *testing.T) {
+	p := trainTiny().PredictDetailed("Good zebra good")
+	if p.TokenCount != 3 || p.KnownTokenCount != 2 || !approxEqual(p.KnownTokenFraction(), 2.0/3) {
+		t.Errorf("PredictDetailed = %d of %d known, want 2 of 3", p.KnownTokenCount, p.TokenCount)
+	}
+	if empty := trainTiny().PredictDetailed("!!!"); !empty.ZeroTokens || empty.KnownTokenFraction() != 0 {
+		t.Errorf("PredictDetailed(!!!) = %+v, want ZeroTokens and a known fraction of 0", empty)
+	}
+}
+
+func TestPredictDetailedZeroTokens(t *testing.T) {
+	nb := trainTiny()
+	for _, text := range []string{"???", "!!! ...", ""} {
+		p := nb.PredictDetailed(text)
+		if !p.ZeroTokens || p.TokenCount != 0 {
+			t.Errorf("PredictDetailed(%q) = %+v, want the zero-token flag", text, p)
+		}
+	}
+	if p := nb.PredictDetailed("good?"); p.ZeroTokens {
+		t.Errorf("PredictDetailed(good?) flagged zero tokens")
+	}
+}
+
+func TestPredictDetailedRunnerUp(t *testing.T) {
+	nb := trainTiny()
+	// P(great|positive) = 2/6 and P(great|negative) = 1/5 with equal priors,
+	// so the posteriors are 5/8 and 3/8.
+	got := nb.PredictDetailed("great")
+	if got.Label != "positive" || got.RunnerUp != "negative" || !approxEqual(got.Margin, 0.25) {
+		t.Errorf("PredictDetailed(great) = %s, runner-up %q, margin %v; want positive, negative, 0.25", got.Label, got.RunnerUp, got.Margin)
+	}
+
+	single := NewNaiveBayesClassifier()
+	single.Train("good", "positive")
+	if got := single.PredictDetailed("good"); got.RunnerUp != "" || got.Margin != 1 {
+		t.Errorf("single class: runner-up %q, margin %v; want none and 1", got.RunnerUp, got.Margin)
+	}
+}
@@ -0,0 +1,212 @@
+package sentiment
+
+import "sort"
+
+// PredictionResult records how a single labeled document was classified.
+type PredictionResult struct {
+	ID         string
+	Text       string
+	Actual     string
+	Predicted  string
+	Confidence float64
+}
+
+// Correct reports whether the prediction matched the actual label.
+func (r PredictionResult) Correct() bool {
+	return r.Predicted == r.Actual
+}
+
+// DetailedMetrics extends Metrics with the per-document results behind them.
+type DetailedMetrics struct {
+	Metrics
+	Results []PredictionResult
+}
+
+// Misclassifications returns the results whose prediction was wrong, in
+// evaluation order.
+func (d DetailedMetrics) Misclassifications() []PredictionResult {
+	var wrong []PredictionResult
+	for _, result := range d.Results {
+		if !result.Correct() {
+			wrong = append(wrong, result)
+		}
+	}
+	return wrong
+}
+
+// EvaluateDetailed runs the classifier against a labeled dataset and returns
+// the aggregate metrics along with every individual prediction.
+func EvaluateDetailed(nb *NaiveBayesClassifier, docs []Document) DetailedMetrics {
+	return EvaluateDetailedWeighted(nb, docs, nil)
+}
+
+// EvaluateDetailedWeighted combines EvaluateDetailed and EvaluateWeighted.
+func EvaluateDetailedWeighted(nb *NaiveBayesClassifier, docs []Document, weights map[string]float64) DetailedMetrics {
+	confusion := make(map[string]map[string]int)
+	results := make([]PredictionResult, 0, len(docs))
+	outcomes := make([]PredictionOutcome, 0, len(docs))
+	correct := 0
+	var weightedTotal, weightedCorrect float64
+
+	for _, doc := range docs {
+		predicted, probs := nb.Predict(doc.Text)
+		actual := nb.canonicalLabel(doc.Label)
+		weight, ok := weights[actual]
+		if !ok {
+			weight = 1
+		}
+		weightedTotal += weight
+		if predicted == actual {
+			correct++
+			weightedCorrect += weight
+		}
+		if _, ok := confusion[actual]; !ok {
+			confusion[actual] = make(map[string]int)
+		}
+		confusion[actual][predicted]++
+		results = append(results, PredictionResult{
+			ID:         doc.ID,
+			Text:       doc.Text,
+			Actual:     actual,
+			Predicted:  predicted,
+			Confidence: probs[predicted],
+		})
+		outcomes = append(outcomes, PredictionOutcome{
+			Confidence: probs[predicted],
+			Correct:    predicted == actual,
+		})
+	}
+
+	return DetailedMetrics{
+		Metrics: Metrics{
+			Total:     len(docs),
+			Correct:   correct,
+			Confusion: confusion,
+
+			WeightedTotal:   weightedTotal,
+			WeightedCorrect: weightedCorrect,
+
+			Outcomes: outcomes,
+		},
+		Results: results,
+	}
+}
+
+// ConfusionCell groups the misclassified documents of one (actual, predicted)
+// pair of the confusion matrix.
+type ConfusionCell struct {
+	Actual    string
+	Predicted string
+	// Count is the total number of documents in the cell.
+	Count int
+	// Examples holds up to the requested number of texts, in evaluation order,
+	// and ExampleIDs the matching document IDs ("" for documents without one).
+	Examples   []string
+	ExampleIDs []string
+}
+
+// ExplainConfusion groups the misclassifications in d by (actual, predicted)
+// pair and keeps up to n example texts per pair. Cells are sorted by actual
+// and then predicted label.
+func ExplainConfusion(d DetailedMetrics, n int) []ConfusionCell {
+	type key struct{ actual, predicted string }
+	cells := make(map[key]*ConfusionCell)
+	for _, result := range d.Misclassifications() {
+		k := key{result.Actual, result.Predicted}
+		cell, ok := cells[k]
+		if !ok {
+			cell = &ConfusionCell{Actual: result.Actual, Predicted: result.Predicted}
+			cells[k] = cell
+		}
+		cell.Count++
+		if len(cell.Examples) < n {
+			cell.Examples = append(cell.Examples, result.Text)
+			cell.ExampleIDs = append(cell.ExampleIDs, result.ID)
+		}
+	}
+
+	out := make([]ConfusionCell, 0, len(cells))
+	for _, cell := range cells {
+		out = append(out, *cell)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Actual != out[j].Actual {
+			return out[i].Actual < out[j].Actual
+		}
+		return out[i].Predicted < out[j].Predicted
+	})
+	return out
+}
+
+// RepresentativeDocuments returns, for every class, the candidate document the
+// model assigns the highest probability of belonging to that class. The
+// classifier does not store its training data, so candidates are supplied by
+// the caller; their labels are ignored. Ties keep the earliest candidate.
+func (nb *NaiveBayesClassifier) RepresentativeDocuments(candidates []Document) map[string]Document {
+	best := make(map[string]Document)
+	bestProb := make(map[string]float64)
+	for _, doc := range candidates {
+		_, probs := nb.Predict(doc.Text)
+		for class, prob := range probs {
+			if current, ok := bestProb[class]; !ok || prob > current {
+				bestProb[class] = prob
+				best[class] = doc
+			}
+		}
+	}
+	return best
+}
+
+// TokenFrequency pairs a token with the number of times it occurred.
+type TokenFrequency struct {
+	Token string
+	Count int
+}
+
+// TopMisclassifiedTokens counts the tokens of every misclassified document in
+// d and returns the n most frequent, highest count first with ties broken
+// alphabetically. When excludeCommon is true, tokens that are at least as
+// frequent (relative to the total token count) in correctly classified
+// documents as in misclassified ones are dropped, leaving the tokens that are
+// over-represented in errors.
+func TopMisclassifiedTokens(nb *NaiveBayesClassifier, d DetailedMetrics, n int, excludeCommon bool) []TokenFrequency {
+	wrong := make(map[string]int)
+	right := make(map[string]int)
+	wrongTotal, rightTotal := 0, 0
+	for _, result := range d.Results {
+		for _, token := range nb.extractFeatures(result.Text) {
+			if token == "" {
+				continue
+			}
+			if result.Correct() {
+				right[token]++
+				rightTotal++
+			} else {
+				wrong[token]++
+				wrongTotal++
+			}
+		}
+	}
+
+	freqs := make([]TokenFrequency, 0, len(wrong))
+	for token, count := range wrong {
+		if excludeCommon && rightTotal > 0 {
+			wrongRate := float64(count) / float64(wrongTotal)
+			rightRate := float64(right[token]) / float64(rightTotal)
+			if rightRate >= wrongRate {
+				continue
+			}
+		}
+		freqs = append(freqs, TokenFrequency{Token: token, Count: count})
+	}
+	sort.Slice(freqs, func(i, j int) bool {
+		if freqs[i].Count != freqs[j].Count {
+			return freqs[i].Count > freqs[j].Count
+		}
+		return freqs[i].Token < freqs[j].Token
+	})
+	if n >= 0 && n < len(freqs) {
+		freqs = freqs[:n]
+	}
+	return freqs
+}
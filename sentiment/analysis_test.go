@@ -0,0 +1,32 @@
+package sentiment
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTopMisclassifiedTokens(t *testing.T) {
+	nb := NewNaiveBayesClassifier()
+	d := DetailedMetrics{Results: []PredictionResult{
+		{Text: "yeah right great", Actual: "negative", Predicted: "positive"},
+		{Text: "Yeah, right. Amazing", Actual: "negative", Predicted: "positive"},
+		{Text: "great film", Actual: "positive", Predicted: "positive"},
+		{Text: "great show", Actual: "positive", Predicted: "positive"},
+	}}
+
+	got := TopMisclassifiedTokens(nb, d, 3, false)
+	want := []TokenFrequency{{"right", 2}, {"yeah", 2}, {"amazing", 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("top 3 = %v, want %v", got, want)
+	}
+
+	// "great" makes up half of the correct tokens but a sixth of the wrong ones.
+	got = TopMisclassifiedTokens(nb, d, -1, true)
+	want = []TokenFrequency{{"right", 2}, {"yeah", 2}, {"amazing", 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("excluding common tokens = %v, want %v", got, want)
+	}
+	if all := TopMisclassifiedTokens(nb, d, -1, false); len(all) != 4 {
+		t.Errorf("all tokens = %v, want 4 entries including great", all)
+	}
+}
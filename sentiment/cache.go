@@ -0,0 +1,95 @@
+package sentiment
+
+import (
+	"container/list"
+	"sync"
+)
+
+// CachedClassifier wraps a classifier with a fixed-size LRU cache of
+// predictions keyed by the exact input text. The cache is dropped whenever
+// the wrapped model changes (Train, Reset, LoadSnapshot, Decay or a setter),
+// so cached results never outlive the model that produced them.
+//
+// Predict is safe for concurrent use under the same rules as the wrapped
+// classifier's Predict: the model must not be mutated concurrently.
+type CachedClassifier struct {
+	model *NaiveBayesClassifier
+	size  int
+
+	mu         sync.Mutex
+	generation uint64
+	order      *list.List
+	entries    map[string]*list.Element
+	hits       uint64
+	misses     uint64
+}
+
+type cachedPrediction struct {
+	text  string
+	label string
+	probs map[string]float64
+}
+
+// NewCachedClassifier returns a wrapper that remembers the predictions for the
+// size most recently classified texts. A size below 1 disables caching.
+func NewCachedClassifier(model *NaiveBayesClassifier, size int) *CachedClassifier {
+	return &CachedClassifier{
+		model:      model,
+		size:       size,
+		generation: model.generation,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Predict returns the cached prediction for text when there is one, and
+// otherwise classifies text with the wrapped model and caches the result.
+// The returned map is a copy the caller may modify.
+func (c *CachedClassifier) Predict(text string) (string, map[string]float64) {
+	if c.size < 1 {
+		return c.model.Predict(text)
+	}
+
+	c.mu.Lock()
+	if c.generation != c.model.generation {
+		c.order.Init()
+		c.entries = make(map[string]*list.Element)
+		c.generation = c.model.generation
+	}
+	if elem, ok := c.entries[text]; ok {
+		c.order.MoveToFront(elem)
+		c.hits++
+		entry := elem.Value.(*cachedPrediction)
+		c.mu.Unlock()
+		return entry.label, copyFloatMap(entry.probs)
+	}
+	c.misses++
+	generation := c.generation
+	c.mu.Unlock()
+
+	label, probs := c.model.Predict(text)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return label, probs
+	}
+	if _, ok := c.entries[text]; !ok {
+		entry := &cachedPrediction{text: text, label: label, probs: copyFloatMap(probs)}
+		c.entries[text] = c.order.PushFront(entry)
+		if c.order.Len() > c.size {
+			oldest := c.order.Back()
+			c.order.Remove(oldest)
+			delete(c.entries, oldest.Value.(*cachedPrediction).text)
+		}
+	}
+	return label, probs
+}
+
+// Stats reports how many Predict calls were served from the cache and how
+// many had to run the model.
+func (c *CachedClassifier) Stats() (hits, misses uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
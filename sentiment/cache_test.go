@@ -0,0 +1,72 @@
+package sentiment
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCachedClassifier(t *testing.T) {
+	nb := trainTiny()
+	c := NewCachedClassifier(nb, 2)
+
+	label, probs := c.Predict("good")
+	probs["positive"] = 0 // the caller's copy must not leak into the cache
+	if again, againProbs := c.Predict("good"); again != label || againProbs["positive"] == 0 {
+		t.Errorf("cached Predict = %s %v, want %s with the original probabilities", again, againProbs, label)
+	}
+	if hits, misses := c.Stats(); hits != 1 || misses != 1 {
+		t.Errorf("Stats = %d hits, %d misses, want 1 and 1", hits, misses)
+	}
+
+	// "good" is evicted once two newer texts are cached.
+	c.Predict("bad")
+	c.Predict("great")
+	c.Predict("good")
+	if hits, misses := c.Stats(); hits != 1 || misses != 4 {
+		t.Errorf("after eviction Stats = %d hits, %d misses, want 1 and 4", hits, misses)
+	}
+
+	for i := 0; i < 5; i++ {
+		nb.Train("bad bad bad", "negative")
+	}
+	want, _ := nb.Predict("good")
+	if got, _ := c.Predict("good"); got != want {
+		t.Errorf("after Train the cache returned %s, want the retrained model's %s", got, want)
+	}
+	if _, misses := c.Stats(); misses != 5 {
+		t.Errorf("misses = %d, want 5: training must invalidate the cache", misses)
+	}
+}
+
+func BenchmarkPredictCached(b *testing.B) {
+	nb := NewNaiveBayesClassifier()
+	nb.TrainBatch(DefaultDataset())
+	texts := make([]string, 1024)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("The storyline was engaging and fun, part %d of the series", i)
+	}
+
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			nb.Predict(texts[i%len(texts)])
+		}
+	})
+	b.Run("hit", func(b *testing.B) {
+		c := NewCachedClassifier(nb, len(texts))
+		for _, text := range texts {
+			c.Predict(text)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			c.Predict(texts[i%len(texts)])
+		}
+	})
+	b.Run("miss", func(b *testing.B) {
+		// Cycling through more texts than the cache holds evicts every entry
+		// before it is asked for again.
+		c := NewCachedClassifier(nb, len(texts)/2)
+		for i := 0; i < b.N; i++ {
+			c.Predict(texts[i%len(texts)])
+		}
+	})
+}
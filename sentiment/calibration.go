@@ -0,0 +1,90 @@
+package sentiment
+
+import "math"
+
+// PredictionOutcome is the confidence (probability of the predicted label) and
+// correctness of a single evaluated prediction.
+type PredictionOutcome struct {
+	Confidence float64
+	Correct    bool
+}
+
+// ConfidenceBucket aggregates the predictions whose confidence falls in
+// [Lower, Upper). The last bucket also includes a confidence of exactly 1.
+type ConfidenceBucket struct {
+	Lower, Upper   float64
+	Count          int
+	Correct        int
+	MeanConfidence float64
+}
+
+// Accuracy returns the fraction of correct predictions in the bucket.
+func (b ConfidenceBucket) Accuracy() float64 {
+	if b.Count == 0 {
+		return 0
+	}
+	return float64(b.Correct) / float64(b.Count)
+}
+
+// ConfidenceHistogram buckets the recorded outcomes into bins equal-width
+// confidence ranges over [0,1], giving a text reliability diagram: in a well
+// calibrated model each bucket's accuracy is close to its mean confidence.
+// Every bucket is returned, including empty ones.
+func (m Metrics) ConfidenceHistogram(bins int) []ConfidenceBucket {
+	if bins <= 0 {
+		return nil
+	}
+	buckets := make([]ConfidenceBucket, bins)
+	width := 1 / float64(bins)
+	for i := range buckets {
+		buckets[i].Lower = float64(i) * width
+		buckets[i].Upper = float64(i+1) * width
+	}
+
+	sums := make([]float64, bins)
+	for _, outcome := range m.Outcomes {
+		idx := int(outcome.Confidence * float64(bins))
+		if idx >= bins {
+			idx = bins - 1
+		}
+		if idx < 0 {
+			idx = 0
+		}
+		buckets[idx].Count++
+		if outcome.Correct {
+			buckets[idx].Correct++
+		}
+		sums[idx] += outcome.Confidence
+	}
+	for i := range buckets {
+		if buckets[i].Count > 0 {
+			buckets[i].MeanConfidence = sums[i] / float64(buckets[i].Count)
+		}
+	}
+	return buckets
+}
+
+// ExpectedCalibrationError returns the ECE of the recorded outcomes: the
+// average over bins equal-width confidence buckets of |accuracy - mean
+// confidence|, weighted by the share of predictions in each bucket. 0 means
+// the confidences match observed accuracy exactly.
+func (m Metrics) ExpectedCalibrationError(bins int) float64 {
+	if len(m.Outcomes) == 0 {
+		return 0
+	}
+	var ece float64
+	for _, bucket := range m.ConfidenceHistogram(bins) {
+		if bucket.Count == 0 {
+			continue
+		}
+		weight := float64(bucket.Count) / float64(len(m.Outcomes))
+		ece += weight * math.Abs(bucket.Accuracy()-bucket.MeanConfidence)
+	}
+	return ece
+}
+
+// ExpectedCalibrationError evaluates nb on docs and returns the expected
+// calibration error over bins confidence buckets.
+func ExpectedCalibrationError(nb *NaiveBayesClassifier, docs []Document, bins int) float64 {
+	return Evaluate(nb, docs).ExpectedCalibrationError(bins)
+}
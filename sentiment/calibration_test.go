@@ -0,0 +1,46 @@
+package sentiment
+
+import "testing"
+
+// outcomes returns n outcomes with confidence conf, the first correct of
+// which are correct.
+func outcomes(n, correct int, conf float64) []PredictionOutcome {
+	out := make([]PredictionOutcome, n)
+	for i := range out {
+		out[i] = PredictionOutcome{Confidence: conf, Correct: i < correct}
+	}
+	return out
+}
+
+func TestExpectedCalibrationError(t *testing.T) {
+	tests := []struct {
+		name     string
+		outcomes []PredictionOutcome
+		want     float64
+	}{
+		{"empty", nil, 0},
+		{"well calibrated", append(outcomes(10, 8, 0.8), outcomes(10, 6, 0.6)...), 0},
+		{"overconfident", outcomes(10, 5, 0.95), 0.45},
+		// 0.4 * |0.5 - 0.65| + 0.6 * |1 - 0.95|
+		{"weighted by bucket size", append(outcomes(4, 2, 0.65), outcomes(6, 6, 0.95)...), 0.09},
+	}
+	for _, tt := range tests {
+		m := Metrics{Outcomes: tt.outcomes}
+		if got := m.ExpectedCalibrationError(10); !approxEqual(got, tt.want) {
+			t.Errorf("%s: ECE = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExpectedCalibrationErrorMatchesEvaluate(t *testing.T) {
+	nb := trainTiny()
+	docs := []Document{
+		{Text: "good great", Label: "positive"},
+		{Text: "bad", Label: "negative"},
+		{Text: "great", Label: "negative"},
+	}
+	want := Evaluate(nb, docs).ExpectedCalibrationError(5)
+	if got := ExpectedCalibrationError(nb, docs, 5); got != want || got <= 0 {
+		t.Errorf("ExpectedCalibrationError = %v, want %v (and above 0 with an error)", got, want)
+	}
+}
@@ -1,78 +1,349 @@
 package sentiment
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"math"
+	"regexp"
 	"sort"
 	"strings"
 	"unicode"
 )
 
-// Document represents a labeled text sample.
+// Document represents a labeled text sample. ID optionally identifies the
+// document in its source data; training ignores it, but evaluation results
+// carry it so errors can be traced back to source rows.
 type Document struct {
+	ID    string
 	Text  string
 	Label string
 }
 
 // NaiveBayesClassifier implements a multinomial Naive Bayes model.
+//
+// Document and word counts are stored as float64 so that options which rescale
+// term frequencies (such as SublinearTF) or fade old evidence (DecayFactor) can
+// keep fractional values.
 type NaiveBayesClassifier struct {
-	classDocCounts  map[string]int
-	classWordCounts map[string]map[string]int
-	classTotalWords map[string]int
+	classDocCounts  map[string]float64
+	classWordCounts map[string]map[string]float64
+	classTotalWords map[string]float64
+	classSingletons map[string]int
 	vocabulary      map[string]struct{}
-	totalDocs       int
+	totalDocs       float64
+
+	tfMode        TermFrequencyMode
+	smoothing     SmoothingStrategy
+	lidstoneAlpha float64
+	priorWeight   float64
+	defaultLabel  string
+
+	positionFeatures bool
+	maxVocabSize     int
+	goodTuringOOV    bool
+	maxNGram         int
+	ngramWeights     map[int]float64
+	stripHTML        bool
+	tokenAllow       *regexp.Regexp
+	tokenDeny        *regexp.Regexp
+	collapseRepeats  int
+	decayFactor      float64
+	vocabFrozen      bool
+	hashBuckets      int
+	labelNormalizer  func(string) string
+	minDocFreq       int
+	maskedTokens     map[string]struct{}
+	classStopWords   map[string]map[string]struct{}
+	splitIdentifiers bool
+	coocWindow       int
+	maxTokenRepeats  int
+	sentenceBreaks   bool
+
+	// generation is bumped by every call that can change predictions, so
+	// wrappers such as CachedClassifier can tell when their results are stale.
+	generation uint64
 }
 
-// NewNaiveBayesClassifier returns an empty classifier.
-func NewNaiveBayesClassifier() *NaiveBayesClassifier {
-	return &NaiveBayesClassifier{
-		classDocCounts:  make(map[string]int),
-		classWordCounts: make(map[string]map[string]int),
-		classTotalWords: make(map[string]int),
+// Option configures optional classifier behaviour.
+type Option func(*NaiveBayesClassifier)
+
+// SublinearTF scales each token's per-document frequency to log(1+count)
+// before it is added to the class counts, so a word repeated many times in a
+// single document no longer contributes linearly. Stored word counts become
+// fractional when this option is enabled. It is shorthand for
+// TermFrequency(TFLog).
+func SublinearTF() Option {
+	return TermFrequency(TFLog)
+}
+
+// PriorWeight multiplies the log-prior term in Predict by w. Values above 1
+// make the class balance matter more, values below 1 let the token likelihoods
+// dominate, and 0 ignores the prior entirely. The default is 1.
+func PriorWeight(w float64) Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.priorWeight = w
+	}
+}
+
+// NewNaiveBayesClassifier returns an empty classifier configured with opts.
+func NewNaiveBayesClassifier(opts ...Option) *NaiveBayesClassifier {
+	nb := &NaiveBayesClassifier{
+		classDocCounts:  make(map[string]float64),
+		classWordCounts: make(map[string]map[string]float64),
+		classTotalWords: make(map[string]float64),
+		classSingletons: make(map[string]int),
 		vocabulary:      make(map[string]struct{}),
+		smoothing:       SmoothingLaplace,
+		lidstoneAlpha:   1,
+		priorWeight:     1,
+		labelNormalizer: NormalizeLabel,
 	}
+	for _, opt := range opts {
+		opt(nb)
+	}
+	return nb
 }
 
-// Reset clears all learned statistics.
+// Reset clears all learned statistics and unfreezes the vocabulary.
+// Configured options are kept.
 func (nb *NaiveBayesClassifier) Reset() {
-	nb.classDocCounts = make(map[string]int)
-	nb.classWordCounts = make(map[string]map[string]int)
-	nb.classTotalWords = make(map[string]int)
+	nb.classDocCounts = make(map[string]float64)
+	nb.classWordCounts = make(map[string]map[string]float64)
+	nb.classTotalWords = make(map[string]float64)
+	nb.classSingletons = make(map[string]int)
 	nb.vocabulary = make(map[string]struct{})
 	nb.totalDocs = 0
+	nb.vocabFrozen = false
+	nb.generation++
+}
+
+// SetDefaultLabel sets the label Predict returns when no class wins, such as
+// when the classifier is untrained or every class scores -Inf.
+func (nb *NaiveBayesClassifier) SetDefaultLabel(label string) {
+	nb.generation++
+	nb.defaultLabel = label
+}
+
+// Labels returns the classes the model has seen, sorted alphabetically.
+func (nb *NaiveBayesClassifier) Labels() []string {
+	labels := make([]string, 0, len(nb.classDocCounts))
+	for label := range nb.classDocCounts {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
 }
 
-// Train ingests a labeled document and updates internal counts.
+// TotalDocs reports how many documents the classifier has been trained on.
+// The value is fractional once counts have been decayed.
+func (nb *NaiveBayesClassifier) TotalDocs() float64 {
+	return nb.totalDocs
+}
+
+// Train ingests a labeled document and updates internal counts. The label is
+// canonicalized first (lowercased by default, see LabelNormalizer).
 func (nb *NaiveBayesClassifier) Train(text, label string) {
-	nb.totalDocs++
-	nb.classDocCounts[label]++
+	nb.TrainWeighted(text, label, 1)
+}
+
+// TrainWeighted ingests a labeled document as if it had been seen weight
+// times: its document count and every token count are multiplied by weight.
+// Weights above 1 let individual documents, such as human corrections, steer
+// the model faster than ordinary training data. Non-positive weights are
+// ignored.
+func (nb *NaiveBayesClassifier) TrainWeighted(text, label string, weight float64) {
+	if weight <= 0 {
+		return
+	}
+	nb.trainFeatures(nb.extractFeatures(text), label, weight)
+}
+
+// trainFeatures adds one document, already reduced to its features, to the
+// counts of label.
+func (nb *NaiveBayesClassifier) trainFeatures(features []string, label string, weight float64) {
+	label = nb.canonicalLabel(label)
+	nb.generation++
+	if nb.decayFactor > 0 && nb.decayFactor < 1 {
+		nb.Decay(nb.decayFactor)
+	}
+	nb.totalDocs += weight
+	nb.classDocCounts[label] += weight
 
 	if _, ok := nb.classWordCounts[label]; !ok {
-		nb.classWordCounts[label] = make(map[string]int)
+		nb.classWordCounts[label] = make(map[string]float64)
 	}
 
-	tokens := tokenize(text)
-	for _, token := range tokens {
+	// Tokens are visited in first-appearance order so floating point sums,
+	// and therefore Fingerprint, do not depend on map iteration order.
+	counts := make(map[string]int)
+	var order []string
+	for _, token := range features {
 		if token == "" {
 			continue
 		}
+		if counts[token] == 0 {
+			order = append(order, token)
+		}
+		counts[token]++
+	}
+
+	for _, token := range order {
+		if nb.vocabFrozen {
+			if _, known := nb.vocabulary[token]; !known {
+				continue
+			}
+		}
+		count := nb.termFrequency(counts[token]) * weight
+		before := nb.classWordCounts[label][token]
 		nb.vocabulary[token] = struct{}{}
-		nb.classWordCounts[label][token]++
-		nb.classTotalWords[label]++
+		nb.classWordCounts[label][token] = before + count
+		nb.classTotalWords[label] += count
+		nb.updateSingletons(label, before, before+count)
 	}
+	nb.enforceVocabCap()
 }
 
-// TrainBatch trains on every document in the slice.
+// TrainBatch trains on every document in the slice. With MinDocumentFrequency
+// set, features found in too few of the documents are dropped before training.
 func (nb *NaiveBayesClassifier) TrainBatch(docs []Document) {
-	for _, doc := range docs {
-		nb.Train(doc.Text, doc.Label)
+	nb.TrainBatchContext(context.Background(), docs)
+}
+
+// TrainBatchContext behaves like TrainBatch but stops ingesting documents once
+// ctx is done, for example when its deadline passes. It returns how many
+// documents were trained, in order from the start of docs, together with
+// ctx.Err() when training stopped early; the model then holds only those
+// documents. With MinDocumentFrequency the frequencies are computed over the
+// whole batch first, and a deadline hit during that pass trains nothing.
+func (nb *NaiveBayesClassifier) TrainBatchContext(ctx context.Context, docs []Document) (int, error) {
+	return nb.TrainBatchCheckpointed(ctx, docs, 0, nil)
+}
+
+// TrainBatchCheckpointed behaves like TrainBatchContext and additionally calls
+// checkpoint with the number of documents trained so far after every `every`
+// documents, typically to save a snapshot so a crash does not lose all
+// progress. The model is consistent whenever checkpoint runs. An error from
+// checkpoint stops training and is returned. A non-positive every or a nil
+// checkpoint disables the callbacks.
+func (nb *NaiveBayesClassifier) TrainBatchCheckpointed(ctx context.Context, docs []Document, every int, checkpoint func(trained int) error) (int, error) {
+	var frequent map[string]bool
+	var features [][]string
+	if nb.minDocFreq > 1 {
+		features = make([][]string, len(docs))
+		for i, doc := range docs {
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+			features[i] = nb.extractFeatures(doc.Text)
+		}
+		frequent = frequentFeatures(features, nb.minDocFreq)
+	}
+	for i, doc := range docs {
+		if err := ctx.Err(); err != nil {
+			return i, err
+		}
+		if frequent == nil {
+			nb.Train(doc.Text, doc.Label)
+		} else {
+			kept := features[i][:0]
+			for _, token := range features[i] {
+				if frequent[token] {
+					kept = append(kept, token)
+				}
+			}
+			nb.trainFeatures(kept, doc.Label, 1)
+		}
+		if checkpoint != nil && every > 0 && (i+1)%every == 0 {
+			if err := checkpoint(i + 1); err != nil {
+				return i + 1, err
+			}
+		}
 	}
+	return len(docs), nil
 }
 
 // Predict scores an unseen text and returns the label with the largest posterior probability.
+// It only reads model state, so concurrent Predict calls are safe as long as no
+// Train, Reset or LoadSnapshot call runs at the same time.
 func (nb *NaiveBayesClassifier) Predict(text string) (string, map[string]float64) {
-	tokens := tokenize(text)
+	return nb.predictFeatures(nb.predictionFeatures(text))
+}
+
+// Prediction is the outcome of PredictDetailed: the label and probabilities
+// Predict returns plus how much of the input the model recognized.
+type Prediction struct {
+	Label         string
+	Probabilities map[string]float64
+	// TokenCount is the number of features extracted from the text and
+	// KnownTokenCount how many of them are in the vocabulary.
+	TokenCount      int
+	KnownTokenCount int
+	// ZeroTokens is set when the text produced no tokens at all, such as
+	// punctuation-only input. The label then comes from the class prior
+	// alone and its confidence says nothing about the text.
+	ZeroTokens bool
+	// RunnerUp is the second most probable class and Margin the probability
+	// gap between the top class and it, small for close calls. With a single
+	// class RunnerUp is empty and Margin is the top probability.
+	RunnerUp string
+	Margin   float64
+	// Entropy is PredictionEntropy of Probabilities.
+	Entropy float64
+}
+
+// KnownTokenFraction returns KnownTokenCount / TokenCount, or 0 when the text
+// produced no tokens.
+func (p Prediction) KnownTokenFraction() float64 {
+	if p.TokenCount == 0 {
+		return 0
+	}
+	return float64(p.KnownTokenCount) / float64(p.TokenCount)
+}
+
+// PredictDetailed behaves like Predict but also reports how many of the
+// text's tokens the model has seen, which tells a prediction backed by
+// evidence apart from one driven by the prior alone.
+func (nb *NaiveBayesClassifier) PredictDetailed(text string) Prediction {
+	tokens, weights := nb.predictionFeatures(text)
+	label, probs := nb.predictFeatures(tokens, weights)
+	prediction := Prediction{Label: label, Probabilities: probs}
+	for _, token := range tokens {
+		if token == "" {
+			continue
+		}
+		prediction.TokenCount++
+		if _, ok := nb.vocabulary[token]; ok {
+			prediction.KnownTokenCount++
+		}
+	}
+	prediction.ZeroTokens = prediction.TokenCount == 0
+	prediction.RunnerUp, prediction.Margin = runnerUp(probs)
+	prediction.Entropy = PredictionEntropy(probs)
+	return prediction
+}
+
+// runnerUp returns the second most probable class and its probability gap to
+// the most probable one.
+func runnerUp(probs map[string]float64) (string, float64) {
+	ranked := RankProbabilities(probs)
+	switch len(ranked) {
+	case 0:
+		return "", 0
+	case 1:
+		return "", ranked[0].Probability
+	}
+	return ranked[1].Label, ranked[0].Probability - ranked[1].Probability
+}
+
+// predictFeatures scores tokens, each scaled by the matching entry of weights
+// or by 1 when weights is nil.
+func (nb *NaiveBayesClassifier) predictFeatures(tokens []string, weights []float64) (string, map[string]float64) {
+	if nb.maxTokenRepeats > 0 {
+		tokens, weights = capRepeats(tokens, weights, nb.maxTokenRepeats)
+	}
 	scores := make(map[string]float64)
-	vocabSize := float64(len(nb.vocabulary))
 
 	bestLabel := ""
 	bestScore := math.Inf(-1)
@@ -81,15 +352,23 @@ func (nb *NaiveBayesClassifier) Predict(text string) (string, map[string]float64
 		if docCount == 0 {
 			continue
 		}
-		logProb := math.Log(float64(docCount) / float64(nb.totalDocs))
-		totalWords := float64(nb.classTotalWords[class])
+		logProb := nb.priorWeight * math.Log(docCount/nb.totalDocs)
 
-		for _, token := range tokens {
+		for i, token := range tokens {
 			if token == "" {
 				continue
 			}
-			wordCount := float64(nb.classWordCounts[class][token])
-			logProb += math.Log((wordCount + 1) / (totalWords + vocabSize))
+			if _, masked := nb.maskedTokens[token]; masked {
+				continue
+			}
+			if _, stop := nb.classStopWords[class][token]; stop {
+				continue
+			}
+			weight := 1.0
+			if weights != nil {
+				weight = weights[i]
+			}
+			logProb += weight * math.Log(nb.wordProbability(class, token))
 		}
 
 		scores[class] = logProb
@@ -99,13 +378,91 @@ func (nb *NaiveBayesClassifier) Predict(text string) (string, map[string]float64
 		}
 	}
 
+	if bestLabel == "" {
+		bestLabel = nb.defaultLabel
+	}
 	return bestLabel, normalizeScores(scores, bestScore)
 }
 
+// WordClassProbabilities returns the smoothed likelihood P(word|class) for
+// every known class. Words outside the vocabulary receive the out-of-vocabulary
+// probability that Predict would assign them.
+func (nb *NaiveBayesClassifier) WordClassProbabilities(word string) map[string]float64 {
+	token := strings.ToLower(strings.TrimSpace(word))
+	if nb.hashBuckets > 0 {
+		token = hashFeature(token, nb.hashBuckets)
+	}
+	probs := make(map[string]float64, len(nb.classDocCounts))
+	for class, docCount := range nb.classDocCounts {
+		if docCount == 0 {
+			continue
+		}
+		probs[class] = nb.wordProbability(class, token)
+	}
+	return probs
+}
+
+// wordProbability returns the smoothed probability of token under class using
+// the Good-Turing estimate when enabled, or the configured smoothing strategy.
+//
+// A class that has documents but no words (for example, only punctuation was
+// trained for it) carries no evidence about tokens, so it falls back to the
+// background distribution pooled over all classes. Its likelihood terms then
+// match an average class and its score is driven by the prior, rather than by
+// an arbitrary smoothing artifact.
+func (nb *NaiveBayesClassifier) wordProbability(class, token string) float64 {
+	if nb.classTotalWords[class] <= 0 {
+		return nb.backgroundProbability(token)
+	}
+	if nb.goodTuringOOV {
+		if prob, ok := nb.goodTuringProbability(class, token); ok && prob > 0 {
+			return prob
+		}
+	}
+	return nb.smoothedProbability(nb.classWordCounts[class][token], nb.classTotalWords[class])
+}
+
+// backgroundProbability returns the smoothed probability of token pooled
+// across every class. With no words trained at all it is uniform.
+func (nb *NaiveBayesClassifier) backgroundProbability(token string) float64 {
+	var count, total float64
+	for class, counts := range nb.classWordCounts {
+		count += counts[token]
+		total += nb.classTotalWords[class]
+	}
+	if total <= 0 {
+		return 1 / float64(len(nb.vocabulary)+1)
+	}
+	return nb.smoothedProbability(count, total)
+}
+
+// smoothedProbability applies the configured additive smoothing to a count
+// out of total words.
+func (nb *NaiveBayesClassifier) smoothedProbability(count, total float64) float64 {
+	alpha := nb.smoothingAlpha()
+	denominator := total + alpha*float64(len(nb.vocabulary))
+	if denominator <= 0 {
+		return unsmoothedFloor
+	}
+	prob := (count + alpha) / denominator
+	if prob <= 0 {
+		return unsmoothedFloor
+	}
+	return prob
+}
+
 func normalizeScores(scores map[string]float64, bestScore float64) map[string]float64 {
 	if len(scores) == 0 {
 		return map[string]float64{}
 	}
+	if math.IsInf(bestScore, -1) {
+		// Every class scored -Inf, so none is more likely than another.
+		uniform := make(map[string]float64, len(scores))
+		for class := range scores {
+			uniform[class] = 1 / float64(len(scores))
+		}
+		return uniform
+	}
 
 	normalized := make(map[string]float64)
 	var sum float64
@@ -131,6 +488,15 @@ type Metrics struct {
 	Total     int
 	Correct   int
 	Confusion map[string]map[string]int
+
+	// WeightedTotal and WeightedCorrect scale each document by the weight of
+	// its actual class. Without class weights they equal Total and Correct.
+	WeightedTotal   float64
+	WeightedCorrect float64
+
+	// Outcomes records the confidence and correctness of every prediction in
+	// evaluation order; see ConfidenceHistogram.
+	Outcomes []PredictionOutcome
 }
 
 // Accuracy returns the accuracy as a floating point value in [0,1].
@@ -141,27 +507,83 @@ func (m Metrics) Accuracy() float64 {
 	return float64(m.Correct) / float64(m.Total)
 }
 
-// Evaluate runs the classifier against a labeled dataset and returns metrics.
-func Evaluate(nb *NaiveBayesClassifier, docs []Document) Metrics {
-	confusion := make(map[string]map[string]int)
-	correct := 0
+// WeightedAccuracy returns the class-weighted accuracy in [0,1].
+func (m Metrics) WeightedAccuracy() float64 {
+	if m.WeightedTotal == 0 {
+		return 0
+	}
+	return m.WeightedCorrect / m.WeightedTotal
+}
 
-	for _, doc := range docs {
-		predicted, _ := nb.Predict(doc.Text)
-		if predicted == doc.Label {
-			correct++
-		}
-		if _, ok := confusion[doc.Label]; !ok {
-			confusion[doc.Label] = make(map[string]int)
-		}
-		confusion[doc.Label][predicted]++
+// Precision returns the fraction of predictions of label that were correct.
+func (m Metrics) Precision(label string) float64 {
+	predicted := 0
+	for _, row := range m.Confusion {
+		predicted += row[label]
+	}
+	if predicted == 0 {
+		return 0
+	}
+	return float64(m.Confusion[label][label]) / float64(predicted)
+}
+
+// Recall returns the fraction of documents labeled label that were predicted as label.
+func (m Metrics) Recall(label string) float64 {
+	support := m.Support(label)
+	if support == 0 {
+		return 0
+	}
+	return float64(m.Confusion[label][label]) / float64(support)
+}
+
+// F1 returns the harmonic mean of Precision and Recall for label.
+func (m Metrics) F1(label string) float64 {
+	p, r := m.Precision(label), m.Recall(label)
+	if p+r == 0 {
+		return 0
+	}
+	return 2 * p * r / (p + r)
+}
+
+// Support returns the number of evaluated documents whose actual label is label.
+func (m Metrics) Support(label string) int {
+	total := 0
+	for _, count := range m.Confusion[label] {
+		total += count
 	}
+	return total
+}
 
-	return Metrics{
-		Total:     len(docs),
-		Correct:   correct,
-		Confusion: confusion,
+// Labels returns every label that appears in the confusion matrix as either
+// an actual or a predicted class, sorted alphabetically.
+func (m Metrics) Labels() []string {
+	seen := make(map[string]struct{})
+	for actual, row := range m.Confusion {
+		seen[actual] = struct{}{}
+		for predicted := range row {
+			if predicted != "" {
+				seen[predicted] = struct{}{}
+			}
+		}
+	}
+	labels := make([]string, 0, len(seen))
+	for label := range seen {
+		labels = append(labels, label)
 	}
+	sort.Strings(labels)
+	return labels
+}
+
+// Evaluate runs the classifier against a labeled dataset and returns metrics.
+func Evaluate(nb *NaiveBayesClassifier, docs []Document) Metrics {
+	return EvaluateDetailed(nb, docs).Metrics
+}
+
+// EvaluateWeighted behaves like Evaluate but also computes a weighted accuracy
+// in which every document counts with the weight of its actual class. Classes
+// missing from weights have weight 1.
+func EvaluateWeighted(nb *NaiveBayesClassifier, docs []Document, weights map[string]float64) Metrics {
+	return EvaluateDetailedWeighted(nb, docs, weights).Metrics
 }
 
 func tokenize(text string) []string {
@@ -186,6 +608,16 @@ var DemoSentences = []string{
 	"What an unforgettable and heartwarming play",
 }
 
+// DemoExpectations maps each DemoSentence with an unambiguous sentiment to the
+// label a model trained on DefaultDataset must predict. The mixed review about
+// delicious food and slow service is deliberately left out, as is the
+// storyline sentence: none of its sentiment words occur in DefaultDataset, so
+// only stop words such as "was" and "the" decide it.
+var DemoExpectations = map[string]string{
+	"Support ignored my emails for weeks":         "negative",
+	"What an unforgettable and heartwarming play": "positive",
+}
+
 var defaultTrainingData = []Document{
 	{Text: "I love this phone, it's fantastic", Label: "positive"},
 	{Text: "The camera is excellent and pictures are great", Label: "positive"},
@@ -211,11 +643,17 @@ var defaultTrainingData = []Document{
 
 // Snapshot captures a serializable view of the trained classifier.
 type Snapshot struct {
-	ClassDocCounts  map[string]int            `json:"class_doc_counts"`
-	ClassWordCounts map[string]map[string]int `json:"class_word_counts"`
-	ClassTotalWords map[string]int            `json:"class_total_words"`
-	Vocabulary      []string                  `json:"vocabulary"`
-	TotalDocs       int                       `json:"total_docs"`
+	ClassDocCounts  map[string]float64            `json:"class_doc_counts"`
+	ClassWordCounts map[string]map[string]float64 `json:"class_word_counts"`
+	ClassTotalWords map[string]float64            `json:"class_total_words"`
+	Vocabulary      []string                      `json:"vocabulary"`
+	TotalDocs       float64                       `json:"total_docs"`
+	Smoothing       SmoothingStrategy             `json:"smoothing,omitempty"`
+	SmoothingAlpha  float64                       `json:"smoothing_alpha,omitempty"`
+	Options         *SnapshotOptions              `json:"options,omitempty"`
+	// Generation is the classifier's update counter when the snapshot was
+	// taken, so a model resumed from a checkpoint continues the count.
+	Generation uint64 `json:"generation,omitempty"`
 }
 
 // Snapshot returns a deep copy of the current classifier state.
@@ -227,44 +665,79 @@ func (nb *NaiveBayesClassifier) Snapshot() Snapshot {
 	sort.Strings(vocab)
 
 	return Snapshot{
-		ClassDocCounts:  copyIntMap(nb.classDocCounts),
+		ClassDocCounts:  copyFloatMap(nb.classDocCounts),
 		ClassWordCounts: copyNestedMap(nb.classWordCounts),
-		ClassTotalWords: copyIntMap(nb.classTotalWords),
+		ClassTotalWords: copyFloatMap(nb.classTotalWords),
 		Vocabulary:      vocab,
 		TotalDocs:       nb.totalDocs,
+		Smoothing:       nb.SmoothingStrategy(),
+		SmoothingAlpha:  nb.lidstoneAlpha,
+		Options:         nb.snapshotOptions(),
+		Generation:      nb.generation,
+	}
+}
+
+// Fingerprint returns a hex-encoded SHA-256 digest of the model state. The
+// snapshot is serialized with sorted keys and vocabulary, so classifiers
+// trained on the same data in the same order share a fingerprint. The
+// generation counter is left out, since it says nothing about the counts.
+func (nb *NaiveBayesClassifier) Fingerprint() string {
+	snapshot := nb.Snapshot()
+	snapshot.Generation = 0
+	payload, err := json.Marshal(snapshot)
+	if err != nil {
+		return ""
 	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
 }
 
 // LoadSnapshot replaces the classifier state with the contents of the snapshot.
+// Settings saved in the snapshot's Options override the classifier's own;
+// older snapshots without Options keep the current settings. The generation
+// resumes from the snapshot's when that is ahead, but always moves forward so
+// caches over this classifier see the change.
 func (nb *NaiveBayesClassifier) LoadSnapshot(snapshot Snapshot) {
-	nb.classDocCounts = copyIntMap(snapshot.ClassDocCounts)
+	nb.generation++
+	if snapshot.Generation > nb.generation {
+		nb.generation = snapshot.Generation
+	}
+	if snapshot.Options != nil {
+		nb.applySnapshotOptions(snapshot.Options)
+	}
+	nb.classDocCounts = copyFloatMap(snapshot.ClassDocCounts)
 	nb.classWordCounts = copyNestedMap(snapshot.ClassWordCounts)
-	nb.classTotalWords = copyIntMap(snapshot.ClassTotalWords)
+	nb.classTotalWords = copyFloatMap(snapshot.ClassTotalWords)
 	nb.vocabulary = make(map[string]struct{}, len(snapshot.Vocabulary))
 	for _, token := range snapshot.Vocabulary {
 		nb.vocabulary[token] = struct{}{}
 	}
 	nb.totalDocs = snapshot.TotalDocs
+	nb.recountSingletons()
+	if snapshot.Smoothing != "" {
+		nb.smoothing = snapshot.Smoothing
+		nb.lidstoneAlpha = snapshot.SmoothingAlpha
+	}
 }
 
-func copyIntMap(src map[string]int) map[string]int {
+func copyFloatMap(src map[string]float64) map[string]float64 {
 	if src == nil {
 		return nil
 	}
-	dst := make(map[string]int, len(src))
+	dst := make(map[string]float64, len(src))
 	for k, v := range src {
 		dst[k] = v
 	}
 	return dst
 }
 
-func copyNestedMap(src map[string]map[string]int) map[string]map[string]int {
+func copyNestedMap(src map[string]map[string]float64) map[string]map[string]float64 {
 	if src == nil {
 		return nil
 	}
-	dst := make(map[string]map[string]int, len(src))
+	dst := make(map[string]map[string]float64, len(src))
 	for k, inner := range src {
-		dst[k] = copyIntMap(inner)
+		dst[k] = copyFloatMap(inner)
 	}
 	return dst
 }
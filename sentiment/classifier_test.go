@@ -0,0 +1,330 @@
+package sentiment
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// approxEqual reports whether a and b agree to within 1e-9.
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// trainTiny returns a Laplace-smoothed classifier over the vocabulary
+// {good, great, bad}: positive has 3 words (good x2, great), negative has
+// 2 words (good, bad).
+func trainTiny(opts ...Option) *NaiveBayesClassifier {
+	nb := NewNaiveBayesClassifier(opts...)
+	nb.Train("good good great", "positive")
+	nb.Train("bad good", "negative")
+	return nb
+}
+
+func TestWordClassProbabilitiesLaplace(t *testing.T) {
+	nb := trainTiny()
+	tests := []struct {
+		word string
+		want map[string]float64
+	}{
+		// (count + 1) / (classTotal + |V|) with |V| = 3.
+		{"good", map[string]float64{"positive": 3.0 / 6, "negative": 2.0 / 5}},
+		{"great", map[string]float64{"positive": 2.0 / 6, "negative": 1.0 / 5}},
+		{"bad", map[string]float64{"positive": 1.0 / 6, "negative": 2.0 / 5}},
+		{" Good ", map[string]float64{"positive": 3.0 / 6, "negative": 2.0 / 5}},
+	}
+	for _, tt := range tests {
+		got := nb.WordClassProbabilities(tt.word)
+		if len(got) != len(tt.want) {
+			t.Fatalf("WordClassProbabilities(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+		for class, want := range tt.want {
+			if !approxEqual(got[class], want) {
+				t.Errorf("P(%q|%s) = %v, want %v", tt.word, class, got[class], want)
+			}
+		}
+	}
+}
+
+func TestWordClassProbabilitiesSumOverVocabulary(t *testing.T) {
+	nb := trainTiny()
+	sums := make(map[string]float64)
+	for _, entry := range nb.Vocabulary() {
+		for class, p := range nb.WordClassProbabilities(entry.Token) {
+			sums[class] += p
+		}
+	}
+	for _, class := range []string{"positive", "negative"} {
+		if !approxEqual(sums[class], 1) {
+			t.Errorf("sum of P(w|%s) over the vocabulary = %v, want 1", class, sums[class])
+		}
+	}
+}
+
+func TestWordClassProbabilitiesUnknownWord(t *testing.T) {
+	nb := trainTiny()
+	got := nb.WordClassProbabilities("zebra")
+	want := map[string]float64{"positive": 1.0 / 6, "negative": 1.0 / 5}
+	for class, p := range want {
+		if !approxEqual(got[class], p) {
+			t.Errorf("P(zebra|%s) = %v, want %v", class, got[class], p)
+		}
+	}
+	if _, known := nb.vocabulary["zebra"]; known {
+		t.Error("querying an unknown word added it to the vocabulary")
+	}
+}
+
+func TestWordClassProbabilitiesUntrained(t *testing.T) {
+	nb := NewNaiveBayesClassifier()
+	if got := nb.WordClassProbabilities("good"); len(got) != 0 {
+		t.Errorf("untrained classifier returned %v, want no classes", got)
+	}
+}
+
+func TestPriorWeightFlipsArgmax(t *testing.T) {
+	train := func(nb *NaiveBayesClassifier) {
+		for i := 0; i < 9; i++ {
+			nb.Train("fine", "positive")
+		}
+		nb.Train("awful", "negative")
+	}
+	tests := []struct {
+		weight float64
+		want   string
+	}{
+		// The likelihood of "awful" favors negative; the 9:1 prior favors positive.
+		{0, "negative"},
+		{0.5, "negative"},
+		{1, "positive"},
+		{2, "positive"},
+	}
+	for _, tt := range tests {
+		nb := NewNaiveBayesClassifier(PriorWeight(tt.weight))
+		train(nb)
+		if got, probs := nb.Predict("awful"); got != tt.want {
+			t.Errorf("PriorWeight(%v): Predict(awful) = %s %v, want %s", tt.weight, got, probs, tt.want)
+		}
+	}
+}
+
+func TestSetDefaultLabel(t *testing.T) {
+	nb := NewNaiveBayesClassifier()
+	if got, _ := nb.Predict("anything"); got != "" {
+		t.Fatalf("untrained Predict = %q, want the empty label before SetDefaultLabel", got)
+	}
+	nb.SetDefaultLabel("unknown")
+	if got, probs := nb.Predict("anything"); got != "unknown" || len(probs) != 0 {
+		t.Errorf("untrained Predict = %q %v, want unknown and no probabilities", got, probs)
+	}
+}
+
+func TestSetDefaultLabelAllClassesNegativeInfinity(t *testing.T) {
+	// An infinite prior weight drives every log prior below 0 to -Inf, so no
+	// class beats the initial best score.
+	nb := NewNaiveBayesClassifier(PriorWeight(math.Inf(1)))
+	nb.Train("good", "positive")
+	nb.Train("bad", "negative")
+	if got, _ := nb.Predict("good"); got != "" {
+		t.Fatalf("Predict = %q, want the empty label before SetDefaultLabel", got)
+	}
+	nb.SetDefaultLabel("unknown")
+	got, probs := nb.Predict("good")
+	if got != "unknown" {
+		t.Errorf("Predict = %q, want unknown", got)
+	}
+	if !approxEqual(probs["positive"], 0.5) || !approxEqual(probs["negative"], 0.5) {
+		t.Errorf("probabilities = %v, want uniform", probs)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := NewNaiveBayesClassifier()
+	a.TrainBatch(DefaultDataset())
+	b := NewNaiveBayesClassifier()
+	b.TrainBatch(DefaultDataset())
+
+	fp := a.Fingerprint()
+	if len(fp) != 64 {
+		t.Fatalf("Fingerprint = %q, want a 64-character hex SHA-256 digest", fp)
+	}
+	if fp != b.Fingerprint() {
+		t.Error("identically trained classifiers have different fingerprints")
+	}
+	if fp != a.Fingerprint() {
+		t.Error("Fingerprint is not stable across calls")
+	}
+
+	restored := NewNaiveBayesClassifier()
+	restored.LoadSnapshot(a.Snapshot())
+	if restored.Fingerprint() != fp {
+		t.Error("a snapshot round-trip changed the fingerprint")
+	}
+
+	b.Train("one more review", "positive")
+	if b.Fingerprint() == fp {
+		t.Error("training another document did not change the fingerprint")
+	}
+	a.SetSmoothingStrategy(SmoothingJeffreys)
+	if a.Fingerprint() == fp {
+		t.Error("changing the smoothing strategy did not change the fingerprint")
+	}
+}
+
+func TestEvaluateWeighted(t *testing.T) {
+	nb := trainTiny()
+	docs := []Document{
+		{Text: "great", Label: "positive"},
+		{Text: "bad", Label: "negative"},
+		{Text: "great", Label: "negative"}, // predicted positive
+	}
+
+	m := EvaluateWeighted(nb, docs, map[string]float64{"negative": 3})
+	if m.Correct != 2 || !approxEqual(m.Accuracy(), 2.0/3) {
+		t.Fatalf("Correct = %d, Accuracy = %v, want 2 and 2/3", m.Correct, m.Accuracy())
+	}
+	// positive counts 1 (the default), negative 3: (1 + 3) / (1 + 3 + 3).
+	if got := m.WeightedAccuracy(); !approxEqual(got, 4.0/7) {
+		t.Errorf("WeightedAccuracy = %v, want 4/7", got)
+	}
+
+	plain := Evaluate(nb, docs)
+	if plain.WeightedAccuracy() != plain.Accuracy() {
+		t.Errorf("unweighted WeightedAccuracy = %v, want Accuracy %v", plain.WeightedAccuracy(), plain.Accuracy())
+	}
+}
+
+func TestEmptyWordClassUsesBackgroundDistribution(t *testing.T) {
+	nb := trainTiny()
+	nb.Train("!!! ???", "punctuation")
+	if nb.classDocCounts["punctuation"] != 1 || nb.classTotalWords["punctuation"] != 0 {
+		t.Fatalf("punctuation class has %v docs and %v words, want 1 and 0",
+			nb.classDocCounts["punctuation"], nb.classTotalWords["punctuation"])
+	}
+
+	// Pooled over all classes: good occurs 3 times in 5 words, |V| = 3.
+	if got := nb.WordClassProbabilities("good")["punctuation"]; !approxEqual(got, 4.0/8) {
+		t.Errorf("P(good|punctuation) = %v, want the background 4/8", got)
+	}
+
+	label, probs := nb.Predict("great great")
+	if label != "positive" {
+		t.Errorf("Predict(great great) = %s %v, want positive", label, probs)
+	}
+	var sum float64
+	for class, p := range probs {
+		if math.IsNaN(p) || p <= 0 {
+			t.Errorf("P(%s) = %v, want a positive probability", class, p)
+		}
+		sum += p
+	}
+	if !approxEqual(sum, 1) {
+		t.Errorf("probabilities sum to %v, want 1", sum)
+	}
+}
+
+// countdownContext reports a passed deadline once Err has been called
+// remaining times, so tests can stop training at an exact document.
+type countdownContext struct {
+	context.Context
+	remaining int
+}
+
+func (c *countdownContext) Err() error {
+	if c.remaining <= 0 {
+		return context.DeadlineExceeded
+	}
+	c.remaining--
+	return nil
+}
+
+func TestTrainBatchContextDeadline(t *testing.T) {
+	docs := DefaultDataset()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+	nb := NewNaiveBayesClassifier()
+	n, err := nb.TrainBatchContext(ctx, docs)
+	if n != 0 || !errors.Is(err, context.DeadlineExceeded) || nb.totalDocs != 0 {
+		t.Errorf("expired deadline: trained %d (%v docs), err %v; want 0 and DeadlineExceeded", n, nb.totalDocs, err)
+	}
+
+	partial := NewNaiveBayesClassifier()
+	n, err = partial.TrainBatchContext(&countdownContext{Context: context.Background(), remaining: 3}, docs)
+	if n != 3 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("deadline after 3 documents: trained %d, err %v", n, err)
+	}
+	want := NewNaiveBayesClassifier()
+	want.TrainBatch(docs[:3])
+	if partial.Fingerprint() != want.Fingerprint() {
+		t.Error("the partial model differs from one trained on the first 3 documents")
+	}
+
+	n, err = NewNaiveBayesClassifier().TrainBatchContext(context.Background(), docs)
+	if n != len(docs) || err != nil {
+		t.Errorf("without a deadline: trained %d, err %v; want %d and nil", n, err, len(docs))
+	}
+}
+
+func TestTrainBatchCheckpointedResume(t *testing.T) {
+	docs := DefaultDataset()
+	single := NewNaiveBayesClassifier()
+	single.TrainBatch(docs)
+
+	// Save a JSON checkpoint every 4 documents and crash after the second.
+	errCrash := errors.New("crash")
+	var saved []byte
+	interrupted := NewNaiveBayesClassifier()
+	trained, err := interrupted.TrainBatchCheckpointed(context.Background(), docs, 4, func(n int) error {
+		payload, err := json.Marshal(interrupted.Snapshot())
+		if err != nil {
+			return err
+		}
+		saved = payload
+		if n == 8 {
+			return errCrash
+		}
+		return nil
+	})
+	if trained != 8 || !errors.Is(err, errCrash) {
+		t.Fatalf("interrupted run trained %d, err %v; want 8 and the checkpoint error", trained, err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(saved, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	resumed := NewNaiveBayesClassifier()
+	resumed.LoadSnapshot(snapshot)
+	resumed.TrainBatch(docs[trained:])
+
+	got, want := resumed.Snapshot(), single.Snapshot()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("resumed snapshot differs from the single pass:\n got %+v\nwant %+v", got, want)
+	}
+	if resumed.generation != single.generation {
+		t.Errorf("resumed generation = %d, want %d", resumed.generation, single.generation)
+	}
+	for _, text := range DemoSentences {
+		gotLabel, gotProbs := resumed.Predict(text)
+		wantLabel, wantProbs := single.Predict(text)
+		if gotLabel != wantLabel || !reflect.DeepEqual(gotProbs, wantProbs) {
+			t.Errorf("Predict(%q) = %s %v after resuming, want %s %v", text, gotLabel, gotProbs, wantLabel, wantProbs)
+		}
+	}
+}
+
+func TestLoadSnapshotAdvancesGeneration(t *testing.T) {
+	nb := trainTiny()
+	nb.Train("more good", "positive")
+	before := nb.generation
+	nb.LoadSnapshot(trainTiny().Snapshot())
+	if nb.generation <= before {
+		t.Errorf("generation went from %d to %d, want it to move forward", before, nb.generation)
+	}
+}
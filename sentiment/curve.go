@@ -0,0 +1,49 @@
+package sentiment
+
+import (
+	"math"
+	"math/rand"
+)
+
+// CurvePoint is one measurement of a learning curve.
+type CurvePoint struct {
+	Fraction  float64
+	TrainSize int
+	Accuracy  float64
+}
+
+// LearningCurve retrains nb on growing fractions of train and evaluates each
+// model against the fixed test set. The training data is shuffled once with
+// seed and every fraction uses a prefix of that order, so larger fractions
+// extend smaller ones and the output is deterministic. Fractions are clamped
+// to at least one document and at most the full training set. nb is left
+// trained on the last fraction.
+func LearningCurve(nb *NaiveBayesClassifier, train, test []Document, fractions []float64, seed int64) []CurvePoint {
+	if len(train) == 0 {
+		return nil
+	}
+	shuffled := append([]Document(nil), train...)
+	rng := rand.New(rand.NewSource(seed))
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	points := make([]CurvePoint, 0, len(fractions))
+	for _, fraction := range fractions {
+		size := int(math.Round(fraction * float64(len(shuffled))))
+		if size < 1 {
+			size = 1
+		}
+		if size > len(shuffled) {
+			size = len(shuffled)
+		}
+		nb.Reset()
+		nb.TrainBatch(shuffled[:size])
+		points = append(points, CurvePoint{
+			Fraction:  fraction,
+			TrainSize: size,
+			Accuracy:  Evaluate(nb, test).Accuracy(),
+		})
+	}
+	return points
+}
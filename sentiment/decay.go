@@ -0,0 +1,34 @@
+package sentiment
+
+// DecayFactor makes the model favour recent documents: before every Train
+// call all existing document and word counts are multiplied by d (0 < d < 1),
+// so evidence seen k documents ago carries weight d^k. Counts become
+// fractional, and each Train call costs an extra pass over the stored counts;
+// for very large vocabularies prefer calling Decay periodically instead.
+// Values outside (0, 1) disable automatic decay.
+func DecayFactor(d float64) Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.decayFactor = d
+	}
+}
+
+// Decay multiplies every stored document and word count by d, fading old
+// evidence so the model adapts to drift. The vocabulary is left unchanged.
+// Values outside (0, 1) are ignored.
+func (nb *NaiveBayesClassifier) Decay(d float64) {
+	if d <= 0 || d >= 1 {
+		return
+	}
+	nb.generation++
+	nb.totalDocs *= d
+	for class := range nb.classDocCounts {
+		nb.classDocCounts[class] *= d
+	}
+	for class, counts := range nb.classWordCounts {
+		for token := range counts {
+			counts[token] *= d
+		}
+		nb.classTotalWords[class] *= d
+	}
+	nb.recountSingletons()
+}
@@ -0,0 +1,54 @@
+package sentiment
+
+import "testing"
+
+func TestDecayFactorFadesOldClass(t *testing.T) {
+	train := func(nb *NaiveBayesClassifier) {
+		for i := 0; i < 10; i++ {
+			nb.Train("the service", "negative")
+		}
+		for i := 0; i < 4; i++ {
+			nb.Train("the service", "positive")
+		}
+	}
+
+	steady := NewNaiveBayesClassifier()
+	train(steady)
+	if got, probs := steady.Predict("the service"); got != "negative" {
+		t.Fatalf("without decay Predict = %s %v, want negative (10 old vs 4 new)", got, probs)
+	}
+
+	decayed := NewNaiveBayesClassifier(DecayFactor(0.5))
+	train(decayed)
+	if got, probs := decayed.Predict("the service"); got != "positive" {
+		t.Errorf("with DecayFactor(0.5) Predict = %s %v, want positive", got, probs)
+	}
+	// Negative evidence was last added four Train calls ago.
+	if got := decayed.classDocCounts["negative"]; got >= 1 {
+		t.Errorf("decayed negative doc count = %v, want below 1", got)
+	}
+}
+
+func TestDecay(t *testing.T) {
+	nb := trainTiny()
+	gen := nb.generation
+	nb.Decay(0.5)
+	if nb.totalDocs != 1 || nb.classDocCounts["positive"] != 0.5 {
+		t.Errorf("totalDocs = %v, positive docs = %v, want 1 and 0.5", nb.totalDocs, nb.classDocCounts["positive"])
+	}
+	if nb.classWordCounts["positive"]["good"] != 1 || nb.classTotalWords["positive"] != 1.5 {
+		t.Errorf("positive good = %v, total words = %v, want 1 and 1.5",
+			nb.classWordCounts["positive"]["good"], nb.classTotalWords["positive"])
+	}
+	if len(nb.vocabulary) != 3 || nb.generation == gen {
+		t.Errorf("vocabulary size = %d, generation changed = %v; want 3 and true", len(nb.vocabulary), nb.generation != gen)
+	}
+
+	for _, d := range []float64{0, 1, -0.5, 2} {
+		before := nb.totalDocs
+		nb.Decay(d)
+		if nb.totalDocs != before {
+			t.Errorf("Decay(%v) changed totalDocs to %v", d, nb.totalDocs)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package sentiment
+
+import "sort"
+
+// ClassDocDelta reports how a class's document count changed between snapshots.
+type ClassDocDelta struct {
+	Class  string
+	Before float64
+	After  float64
+	Delta  float64
+}
+
+// TokenCountDelta reports how a token's count within a class changed.
+type TokenCountDelta struct {
+	Class  string
+	Token  string
+	Before float64
+	After  float64
+	Delta  float64
+}
+
+// SnapshotDiff summarizes the changes from one snapshot to another. Every
+// slice is sorted (by class, then token) so diffs are stable to compare.
+type SnapshotDiff struct {
+	AddedTokens     []string
+	RemovedTokens   []string
+	VocabularyDelta int
+	ClassDocDeltas  []ClassDocDelta
+	TokenDeltas     []TokenCountDelta
+	TotalDocsDelta  float64
+}
+
+// DiffSnapshots reports vocabulary tokens added and removed going from a to b,
+// the per-class document and token count changes, and the total document
+// delta. Classes and tokens whose counts did not change are omitted.
+func DiffSnapshots(a, b Snapshot) SnapshotDiff {
+	diff := SnapshotDiff{
+		VocabularyDelta: len(b.Vocabulary) - len(a.Vocabulary),
+		TotalDocsDelta:  b.TotalDocs - a.TotalDocs,
+	}
+
+	before := make(map[string]struct{}, len(a.Vocabulary))
+	for _, token := range a.Vocabulary {
+		before[token] = struct{}{}
+	}
+	after := make(map[string]struct{}, len(b.Vocabulary))
+	for _, token := range b.Vocabulary {
+		after[token] = struct{}{}
+		if _, ok := before[token]; !ok {
+			diff.AddedTokens = append(diff.AddedTokens, token)
+		}
+	}
+	for _, token := range a.Vocabulary {
+		if _, ok := after[token]; !ok {
+			diff.RemovedTokens = append(diff.RemovedTokens, token)
+		}
+	}
+	sort.Strings(diff.AddedTokens)
+	sort.Strings(diff.RemovedTokens)
+
+	for _, class := range unionKeys(a.ClassDocCounts, b.ClassDocCounts) {
+		was, now := a.ClassDocCounts[class], b.ClassDocCounts[class]
+		if was != now {
+			diff.ClassDocDeltas = append(diff.ClassDocDeltas, ClassDocDelta{Class: class, Before: was, After: now, Delta: now - was})
+		}
+	}
+
+	for _, class := range unionKeys(a.ClassWordCounts, b.ClassWordCounts) {
+		wasCounts, nowCounts := a.ClassWordCounts[class], b.ClassWordCounts[class]
+		for _, token := range unionKeys(wasCounts, nowCounts) {
+			was, now := wasCounts[token], nowCounts[token]
+			if was != now {
+				diff.TokenDeltas = append(diff.TokenDeltas, TokenCountDelta{Class: class, Token: token, Before: was, After: now, Delta: now - was})
+			}
+		}
+	}
+	return diff
+}
+
+// unionKeys returns the sorted union of the keys of a and b.
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]struct{}, len(a)+len(b))
+	for k := range a {
+		seen[k] = struct{}{}
+	}
+	for k := range b {
+		seen[k] = struct{}{}
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,53 @@
+package sentiment
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	a := Snapshot{
+		ClassDocCounts: map[string]float64{"positive": 2, "negative": 1},
+		ClassWordCounts: map[string]map[string]float64{
+			"positive": {"good": 2, "fine": 1},
+			"negative": {"bad": 1},
+		},
+		Vocabulary: []string{"bad", "fine", "good"},
+		TotalDocs:  3,
+	}
+	b := Snapshot{
+		ClassDocCounts: map[string]float64{"positive": 2, "negative": 3, "neutral": 1},
+		ClassWordCounts: map[string]map[string]float64{
+			"positive": {"good": 2},
+			"negative": {"bad": 2, "awful": 1},
+			"neutral":  {"okay": 1},
+		},
+		Vocabulary: []string{"awful", "bad", "good", "okay"},
+		TotalDocs:  6,
+	}
+
+	got := DiffSnapshots(a, b)
+	want := SnapshotDiff{
+		AddedTokens:     []string{"awful", "okay"},
+		RemovedTokens:   []string{"fine"},
+		VocabularyDelta: 1,
+		ClassDocDeltas: []ClassDocDelta{
+			{Class: "negative", Before: 1, After: 3, Delta: 2},
+			{Class: "neutral", Before: 0, After: 1, Delta: 1},
+		},
+		TokenDeltas: []TokenCountDelta{
+			{Class: "negative", Token: "awful", Before: 0, After: 1, Delta: 1},
+			{Class: "negative", Token: "bad", Before: 1, After: 2, Delta: 1},
+			{Class: "neutral", Token: "okay", Before: 0, After: 1, Delta: 1},
+			{Class: "positive", Token: "fine", Before: 1, After: 0, Delta: -1},
+		},
+		TotalDocsDelta: 3,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DiffSnapshots =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if empty := DiffSnapshots(a, a); !reflect.DeepEqual(empty, SnapshotDiff{}) {
+		t.Errorf("diff of a snapshot with itself = %+v, want empty", empty)
+	}
+}
@@ -0,0 +1,125 @@
+package sentiment
+
+import "fmt"
+
+// EnsembleMember is a named model taking part in an Ensemble. Weight scales
+// the member's influence in the mean and vote modes; zero or negative
+// weights count as 1.
+type EnsembleMember struct {
+	Name   string
+	Model  *NaiveBayesClassifier
+	Weight float64
+}
+
+func (m EnsembleMember) weight() float64 {
+	if m.Weight <= 0 {
+		return 1
+	}
+	return m.Weight
+}
+
+// MemberPrediction is one member's individual prediction for a text.
+type MemberPrediction struct {
+	Name          string
+	Label         string
+	Probabilities map[string]float64
+}
+
+// AggregationMode controls how an Ensemble combines its members' predictions.
+type AggregationMode string
+
+const (
+	// AggregateMean averages the members' probabilities, weighted by member
+	// weight (the default).
+	AggregateMean AggregationMode = "mean"
+	// AggregateVote gives each member's predicted label the member's weight
+	// and reports every label's share of the total weight.
+	AggregateVote AggregationMode = "vote"
+	// AggregateMax reports, for every class, the highest probability any
+	// member assigned it, so the most confident member decides. The combined
+	// values are not renormalized and need not sum to 1.
+	AggregateMax AggregationMode = "max"
+)
+
+// ParseAggregationMode converts a mode name into an AggregationMode.
+func ParseAggregationMode(name string) (AggregationMode, error) {
+	switch mode := AggregationMode(name); mode {
+	case AggregateMean, AggregateVote, AggregateMax:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown aggregation mode %q (expected mean|vote|max)", name)
+}
+
+// Ensemble combines several classifiers according to Mode, averaging their
+// class probabilities when Mode is empty. A class unknown to a member counts
+// as probability 0 for that member.
+type Ensemble struct {
+	Members []EnsembleMember
+	Mode    AggregationMode
+}
+
+// NewEnsemble returns an ensemble of the given members.
+func NewEnsemble(members ...EnsembleMember) *Ensemble {
+	return &Ensemble{Members: members}
+}
+
+// Predict returns the label with the highest combined score together with
+// the combined distribution. In every mode ties go to the alphabetically
+// first label, so a split vote between "negative" and "positive" yields
+// "negative". An empty ensemble returns "" and a nil map.
+func (e *Ensemble) Predict(text string) (string, map[string]float64) {
+	_, label, probs := e.PredictEach(text)
+	return label, probs
+}
+
+// PredictEach runs every member on text and returns their individual
+// predictions, in member order, alongside the ensemble's combined result.
+func (e *Ensemble) PredictEach(text string) ([]MemberPrediction, string, map[string]float64) {
+	if len(e.Members) == 0 {
+		return nil, "", nil
+	}
+	members := make([]MemberPrediction, len(e.Members))
+	for i, member := range e.Members {
+		label, probs := member.Model.Predict(text)
+		members[i] = MemberPrediction{Name: member.Name, Label: label, Probabilities: probs}
+	}
+	combined := e.combine(members)
+	ranked := RankProbabilities(combined)
+	if len(ranked) == 0 {
+		return members, "", combined
+	}
+	return members, ranked[0].Label, combined
+}
+
+// combine merges the member predictions according to e.Mode.
+func (e *Ensemble) combine(members []MemberPrediction) map[string]float64 {
+	combined := make(map[string]float64)
+	var total float64
+	for i, m := range members {
+		weight := e.Members[i].weight()
+		switch e.Mode {
+		case AggregateVote:
+			if m.Label != "" {
+				combined[m.Label] += weight
+				total += weight
+			}
+		case AggregateMax:
+			for class, p := range m.Probabilities {
+				if current, ok := combined[class]; !ok || p > current {
+					combined[class] = p
+				}
+			}
+		default:
+			for class, p := range m.Probabilities {
+				combined[class] += weight * p
+			}
+			total += weight
+		}
+	}
+	if e.Mode != AggregateMax && total > 0 {
+		for class := range combined {
+			combined[class] /= total
+		}
+	}
+	return combined
+}
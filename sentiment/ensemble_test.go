@@ -0,0 +1,61 @@
+package sentiment
+
+import "testing"
+
+func TestEnsembleAggregationModesDisagree(t *testing.T) {
+	// One member favors x; two favor y with z a close second, so z has the
+	// highest average, y the most votes and x the single highest probability.
+	members := []MemberPrediction{
+		{Name: "a", Label: "x", Probabilities: map[string]float64{"x": 0.6, "y": 0, "z": 0.4}},
+		{Name: "b", Label: "y", Probabilities: map[string]float64{"x": 0.05, "y": 0.5, "z": 0.45}},
+		{Name: "c", Label: "y", Probabilities: map[string]float64{"x": 0.05, "y": 0.5, "z": 0.45}},
+	}
+	tests := []struct {
+		mode  AggregationMode
+		want  string
+		probs map[string]float64
+	}{
+		{AggregateMean, "z", map[string]float64{"x": 0.7 / 3, "y": 1.0 / 3, "z": 1.3 / 3}},
+		{AggregateVote, "y", map[string]float64{"x": 1.0 / 3, "y": 2.0 / 3}},
+		{AggregateMax, "x", map[string]float64{"x": 0.6, "y": 0.5, "z": 0.45}},
+	}
+	for _, tt := range tests {
+		e := &Ensemble{Members: make([]EnsembleMember, len(members)), Mode: tt.mode}
+		combined := e.combine(members)
+		if got := RankProbabilities(combined)[0].Label; got != tt.want {
+			t.Errorf("%s: winner = %s %v, want %s", tt.mode, got, combined, tt.want)
+		}
+		if len(combined) != len(tt.probs) {
+			t.Errorf("%s: combined = %v, want %v", tt.mode, combined, tt.probs)
+		}
+		for class, want := range tt.probs {
+			if !approxEqual(combined[class], want) {
+				t.Errorf("%s: %s = %v, want %v", tt.mode, class, combined[class], want)
+			}
+		}
+	}
+
+	// Weighting the lone x voter 3 outvotes the two y voters.
+	weighted := &Ensemble{Members: []EnsembleMember{{Weight: 3}, {}, {}}, Mode: AggregateVote}
+	if got := RankProbabilities(weighted.combine(members))[0].Label; got != "x" {
+		t.Errorf("weighted vote winner = %s, want x", got)
+	}
+}
+
+func TestEnsembleVoteTie(t *testing.T) {
+	pos := NewNaiveBayesClassifier()
+	pos.Train("good", "positive")
+	pos.Train("bad", "negative")
+	pos.Train("good", "positive")
+	neg := NewNaiveBayesClassifier()
+	neg.Train("good", "negative")
+	neg.Train("bad", "positive")
+	neg.Train("good", "negative")
+
+	e := NewEnsemble(EnsembleMember{Name: "pos", Model: pos}, EnsembleMember{Name: "neg", Model: neg})
+	e.Mode = AggregateVote
+	label, probs := e.Predict("good")
+	if label != "negative" || probs["negative"] != 0.5 || probs["positive"] != 0.5 {
+		t.Errorf("split vote = %s %v, want negative at 0.5 each", label, probs)
+	}
+}
@@ -0,0 +1,58 @@
+package sentiment
+
+import (
+	"math"
+	"sort"
+)
+
+// PredictionEntropy returns the Shannon entropy, in bits, of a probability
+// distribution such as the one Predict returns. It is 0 for a prediction that
+// puts all mass on one class and log2(n) for a uniform distribution over n
+// classes, so higher values mean a less certain prediction. Zero and
+// non-finite probabilities contribute nothing.
+func PredictionEntropy(probs map[string]float64) float64 {
+	var entropy float64
+	for _, p := range probs {
+		if p > 0 && !math.IsInf(p, 0) {
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy
+}
+
+// UncertainText is an unlabeled text scored by how unsure the model is
+// about it.
+type UncertainText struct {
+	Text    string
+	Label   string
+	Entropy float64
+	Margin  float64
+}
+
+// MostUncertain predicts every text and returns up to n of them ordered from
+// most to least uncertain, the ones most worth labeling next in active
+// learning. Texts are ranked by PredictionEntropy, then by the smaller
+// margin to the runner-up class, then by input order. A non-positive n
+// returns them all.
+func (nb *NaiveBayesClassifier) MostUncertain(texts []string, n int) []UncertainText {
+	scored := make([]UncertainText, len(texts))
+	for i, text := range texts {
+		prediction := nb.PredictDetailed(text)
+		scored[i] = UncertainText{
+			Text:    text,
+			Label:   prediction.Label,
+			Entropy: prediction.Entropy,
+			Margin:  prediction.Margin,
+		}
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		if scored[i].Entropy != scored[j].Entropy {
+			return scored[i].Entropy > scored[j].Entropy
+		}
+		return scored[i].Margin < scored[j].Margin
+	})
+	if n > 0 && n < len(scored) {
+		scored = scored[:n]
+	}
+	return scored
+}
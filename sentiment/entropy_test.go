@@ -0,0 +1,73 @@
+package sentiment
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPredictionEntropy(t *testing.T) {
+	tests := []struct {
+		name  string
+		probs map[string]float64
+		want  float64
+	}{
+		{"certain", map[string]float64{"positive": 1, "negative": 0}, 0},
+		{"confident", map[string]float64{"positive": 0.99, "negative": 0.01}, -(0.99*math.Log2(0.99) + 0.01*math.Log2(0.01))},
+		{"uniform two", map[string]float64{"positive": 0.5, "negative": 0.5}, 1},
+		{"uniform three", map[string]float64{"positive": 1.0 / 3, "neutral": 1.0 / 3, "negative": 1.0 / 3}, math.Log2(3)},
+		{"empty", nil, 0},
+	}
+	for _, tt := range tests {
+		if got := PredictionEntropy(tt.probs); !approxEqual(got, tt.want) {
+			t.Errorf("%s: PredictionEntropy = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	nb := trainTiny()
+	_, confident := nb.Predict("great great great great")
+	_, flat := nb.Predict("zebra")
+	if PredictionEntropy(confident) >= PredictionEntropy(flat) {
+		t.Errorf("confident prediction %v has entropy %v, not below the near-uniform %v at %v",
+			confident, PredictionEntropy(confident), flat, PredictionEntropy(flat))
+	}
+}
+
+func TestMostUncertain(t *testing.T) {
+	nb := trainTiny()
+	texts := []string{
+		"great great great great",
+		"zebra",
+		"bad bad",
+		"good",
+		"yak", // out of vocabulary like zebra, so tied with it
+		"great",
+	}
+	got := nb.MostUncertain(texts, 0)
+	if len(got) != len(texts) {
+		t.Fatalf("MostUncertain returned %d texts, want %d", len(got), len(texts))
+	}
+	for i, u := range got {
+		_, probs := nb.Predict(u.Text)
+		if want := PredictionEntropy(probs); !approxEqual(u.Entropy, want) {
+			t.Errorf("%q entropy = %v, want %v", u.Text, u.Entropy, want)
+		}
+		if i > 0 && u.Entropy > got[i-1].Entropy {
+			t.Errorf("%q (entropy %v) ranks below %q (entropy %v)", u.Text, u.Entropy, got[i-1].Text, got[i-1].Entropy)
+		}
+	}
+	var order []string
+	for _, u := range got {
+		order = append(order, u.Text)
+	}
+	// P(positive) for good is 5/9 (entropy 0.991), for the OOV texts 5/11
+	// (0.994), for great 5/8 (0.954); bad bad and great x4 are confident.
+	want := []string{"zebra", "yak", "good", "great", "bad bad", "great great great great"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("order = %q, want %q", order, want)
+	}
+
+	if top := nb.MostUncertain(texts, 2); len(top) != 2 || top[0].Text != "zebra" || top[1].Text != "yak" {
+		t.Errorf("MostUncertain(n=2) = %+v, want zebra and yak", top)
+	}
+}
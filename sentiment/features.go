@@ -0,0 +1,340 @@
+package sentiment
+
+import (
+	"hash/fnv"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ngramSeparator joins the words of an n-gram feature. Base tokens never
+// contain spaces, so the number of separators identifies the n-gram order.
+const ngramSeparator = " "
+
+// coocPrefix marks co-occurrence features added by CoOccurrence.
+const coocPrefix = "cooc_"
+
+// sentenceBreakToken is the pseudo-token SentenceBreaks adds per sentence
+// ending. Real tokens never contain underscores, so it cannot collide with one.
+const sentenceBreakToken = "sent_break"
+
+// PositionFeatures adds position-bucketed copies of the first and last tokens
+// (for example "good@start" and "good@end") alongside the plain tokens. This
+// captures a little word order for short texts but enlarges the vocabulary,
+// so it is off by default.
+func PositionFeatures() Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.positionFeatures = true
+	}
+}
+
+// StripHTML removes HTML tags and decodes entities such as &amp; before
+// tokenizing, so markup like <br> does not turn into junk tokens. Leave it off
+// for plain-text inputs.
+func StripHTML() Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.stripHTML = true
+	}
+}
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// stripHTMLMarkup replaces tags with spaces, decodes entities and collapses
+// the resulting whitespace.
+func stripHTMLMarkup(text string) string {
+	text = htmlTagPattern.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// CollapseRepeatedChars shortens any character repeated three or more times
+// in a row within a token to keep repetitions (1 or 2), so elongations such as
+// "sooo" and "soooooo" share a single feature. Values outside 1..2 disable it.
+func CollapseRepeatedChars(keep int) Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.collapseRepeats = keep
+	}
+}
+
+// collapseRepeats rewrites runs of three or more identical runes to keep runes.
+func collapseRepeats(token string, keep int) string {
+	runes := []rune(token)
+	out := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && runes[j] == runes[i] {
+			j++
+		}
+		run := j - i
+		if run >= 3 {
+			run = keep
+		}
+		for k := 0; k < run; k++ {
+			out = append(out, runes[i])
+		}
+		i = j
+	}
+	return string(out)
+}
+
+// TokenAllowPattern keeps only tokens matching re; all other tokens are
+// dropped in both Train and Predict.
+func TokenAllowPattern(re *regexp.Regexp) Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.tokenAllow = re
+	}
+}
+
+// TokenDenyPattern drops tokens matching re in both Train and Predict. Because
+// tokenizing splits on punctuation, the pattern is also matched against each
+// whitespace-separated word beforehand, so a pattern such as `^https?://`
+// removes whole URLs rather than leaving their pieces behind.
+func TokenDenyPattern(re *regexp.Regexp) Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.tokenDeny = re
+	}
+}
+
+// filterTokens applies the allow and deny patterns to tokens in place.
+func (nb *NaiveBayesClassifier) filterTokens(tokens []string) []string {
+	if nb.tokenAllow == nil && nb.tokenDeny == nil {
+		return tokens
+	}
+	kept := tokens[:0]
+	for _, token := range tokens {
+		if nb.tokenAllow != nil && !nb.tokenAllow.MatchString(token) {
+			continue
+		}
+		if nb.tokenDeny != nil && nb.tokenDeny.MatchString(token) {
+			continue
+		}
+		kept = append(kept, token)
+	}
+	return kept
+}
+
+// dropDeniedWords removes whitespace-separated words matching the deny pattern.
+func (nb *NaiveBayesClassifier) dropDeniedWords(text string) string {
+	words := strings.Fields(text)
+	kept := words[:0]
+	for _, word := range words {
+		if !nb.tokenDeny.MatchString(word) {
+			kept = append(kept, word)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// SplitIdentifiers splits camelCase and PascalCase identifiers into their
+// words before tokenizing, so "getUserName" yields get, user and name.
+// Acronyms stay together ("parseHTTPResponse" yields parse, http, response).
+// snake_case is always split, since underscores separate tokens anyway.
+func SplitIdentifiers() Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.splitIdentifiers = true
+	}
+}
+
+// splitCamelCase inserts a space at every lower-to-upper case boundary and
+// before the last capital of an upper-case run followed by a lower-case
+// letter.
+func splitCamelCase(text string) string {
+	runes := []rune(text)
+	var b strings.Builder
+	b.Grow(len(text) + 8)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// NGrams adds contiguous word n-grams of order 2 through maxOrder (for example
+// "not good" when maxOrder is 2) as features alongside the unigrams. Values
+// below 2 keep the classifier unigram-only.
+func NGrams(maxOrder int) Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.maxNGram = maxOrder
+	}
+}
+
+// CoOccurrence adds a "cooc_a_b" feature for every ordered pair of words at
+// most window positions apart, adjacent or not, so that "not" in "not one I
+// would recommend" still pairs with "recommend" as cooc_not_recommend. Values
+// below 1 disable the features.
+func CoOccurrence(window int) Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.coocWindow = window
+	}
+}
+
+// coocFeatures returns the co-occurrence features of words within window.
+func coocFeatures(words []string, window int) []string {
+	var features []string
+	for i, word := range words {
+		for j := i + 1; j <= i+window && j < len(words); j++ {
+			features = append(features, coocPrefix+word+"_"+words[j])
+		}
+	}
+	return features
+}
+
+// SentenceBreaks adds a "sent_break" feature for every sentence-ending run of
+// '.', '!' or '?' in the text, so the number of sentences becomes a signal:
+// "Great! Loved it! Wow!" carries three breaks where one long sentence
+// carries one. A run such as "?!" or "..." counts once; the periods of
+// abbreviations like "Mr." and of decimals like "3.5" count as breaks too.
+func SentenceBreaks() Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.sentenceBreaks = true
+	}
+}
+
+// countSentenceBreaks returns the number of runs of sentence-ending
+// punctuation in text.
+func countSentenceBreaks(text string) int {
+	breaks := 0
+	inRun := false
+	for _, r := range text {
+		ending := r == '.' || r == '!' || r == '?'
+		if ending && !inRun {
+			breaks++
+		}
+		inRun = ending
+	}
+	return breaks
+}
+
+// NGramWeights scales the contribution of each feature to the Predict log sum
+// by the weight registered for its n-gram order (1 for unigrams, 2 for
+// bigrams, ...). Orders without an entry keep the default weight of 1. Only
+// the contiguous n-grams of NGrams have a higher order: co-occurrence,
+// sentence break and position features such as "cooc_not_good" and
+// "good@start" count as order 1 and get the unigram weight.
+func NGramWeights(weights map[int]float64) Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.ngramWeights = make(map[int]float64, len(weights))
+		for order, weight := range weights {
+			nb.ngramWeights[order] = weight
+		}
+	}
+}
+
+// featureWeight returns the Predict multiplier for token based on its n-gram order.
+func (nb *NaiveBayesClassifier) featureWeight(token string) float64 {
+	if len(nb.ngramWeights) == 0 {
+		return 1
+	}
+	order := strings.Count(token, ngramSeparator) + 1
+	if weight, ok := nb.ngramWeights[order]; ok {
+		return weight
+	}
+	return 1
+}
+
+// FeatureHashing maps every feature into one of buckets hash buckets (the
+// hashing trick) instead of storing the feature itself, so memory stays
+// bounded no matter how many distinct tokens are seen. Distinct tokens that
+// hash to the same bucket share counts, trading some accuracy for size, and
+// the vocabulary then holds bucket names such as "#1234". NGramWeights still
+// apply, since each feature's weight is looked up before it is hashed. Values
+// below 1 disable hashing.
+func FeatureHashing(buckets int) Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.hashBuckets = buckets
+	}
+}
+
+// hashFeature returns the bucket name for token.
+func hashFeature(token string, buckets int) string {
+	h := fnv.New32a()
+	h.Write([]byte(token))
+	return "#" + strconv.FormatUint(uint64(h.Sum32()%uint32(buckets)), 10)
+}
+
+// Tokenize returns the features the classifier derives from text, after every
+// configured preprocessing and feature option. It is what Train and Predict
+// see, which makes it useful for debugging tokenizer settings.
+func (nb *NaiveBayesClassifier) Tokenize(text string) []string {
+	return nb.extractFeatures(text)
+}
+
+// extractFeatures tokenizes text and applies the configured feature options.
+// Train and Predict both go through it so the feature space stays consistent.
+func (nb *NaiveBayesClassifier) extractFeatures(text string) []string {
+	return nb.hashFeatures(nb.unhashedFeatures(text))
+}
+
+// predictionFeatures returns the features Predict scores for text together
+// with their NGramWeights multipliers, or nil weights when none are set. The
+// weights are looked up before FeatureHashing replaces the features with
+// bucket names, which no longer tell the n-gram order.
+func (nb *NaiveBayesClassifier) predictionFeatures(text string) ([]string, []float64) {
+	tokens := nb.unhashedFeatures(text)
+	var weights []float64
+	if len(nb.ngramWeights) > 0 {
+		weights = make([]float64, len(tokens))
+		for i, token := range tokens {
+			weights[i] = nb.featureWeight(token)
+		}
+	}
+	return nb.hashFeatures(tokens), weights
+}
+
+// hashFeatures replaces tokens in place with their FeatureHashing buckets.
+func (nb *NaiveBayesClassifier) hashFeatures(tokens []string) []string {
+	if nb.hashBuckets > 0 {
+		for i, token := range tokens {
+			tokens[i] = hashFeature(token, nb.hashBuckets)
+		}
+	}
+	return tokens
+}
+
+// unhashedFeatures returns the features of text before FeatureHashing.
+func (nb *NaiveBayesClassifier) unhashedFeatures(text string) []string {
+	if nb.stripHTML {
+		text = stripHTMLMarkup(text)
+	}
+	if nb.tokenDeny != nil {
+		text = nb.dropDeniedWords(text)
+	}
+	if nb.splitIdentifiers {
+		text = splitCamelCase(text)
+	}
+	breaks := 0
+	if nb.sentenceBreaks {
+		breaks = countSentenceBreaks(text)
+	}
+	words := tokenize(text)
+	if nb.collapseRepeats == 1 || nb.collapseRepeats == 2 {
+		for i, word := range words {
+			words[i] = collapseRepeats(word, nb.collapseRepeats)
+		}
+	}
+	words = nb.filterTokens(words)
+	tokens := words
+	for n := 2; n <= nb.maxNGram; n++ {
+		for i := 0; i+n <= len(words); i++ {
+			tokens = append(tokens, strings.Join(words[i:i+n], ngramSeparator))
+		}
+	}
+	if nb.coocWindow > 0 {
+		tokens = append(tokens, coocFeatures(words, nb.coocWindow)...)
+	}
+	for i := 0; i < breaks; i++ {
+		tokens = append(tokens, sentenceBreakToken)
+	}
+	if nb.positionFeatures && len(words) > 0 {
+		tokens = append(tokens, words[0]+"@start", words[len(words)-1]+"@end")
+	}
+	return tokens
+}
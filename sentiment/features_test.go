@@ -0,0 +1,373 @@
+package sentiment
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPositionFeatures(t *testing.T) {
+	nb := NewNaiveBayesClassifier(PositionFeatures())
+	got := nb.Tokenize("Good movie, not good")
+	want := []string{"good", "movie", "not", "good", "good@start", "good@end"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Tokenize = %q, want %q", got, want)
+	}
+
+	nb.Train("good movie, not good", "negative")
+	for _, token := range []string{"good", "good@start", "good@end"} {
+		if _, ok := nb.vocabulary[token]; !ok {
+			t.Errorf("vocabulary is missing %q", token)
+		}
+	}
+	if nb.classWordCounts["negative"]["good"] != 2 ||
+		nb.classWordCounts["negative"]["good@start"] != 1 ||
+		nb.classWordCounts["negative"]["good@end"] != 1 {
+		t.Errorf("counts = %v, want good=2 and one each of good@start and good@end", nb.classWordCounts["negative"])
+	}
+
+	if got := NewNaiveBayesClassifier().Tokenize("Good movie, not good"); len(got) != 4 {
+		t.Errorf("without PositionFeatures Tokenize = %q, want the 4 plain tokens", got)
+	}
+	if got := nb.Tokenize("great"); !reflect.DeepEqual(got, []string{"great", "great@start", "great@end"}) {
+		t.Errorf("single token Tokenize = %q", got)
+	}
+	if got := nb.Tokenize("!!!"); len(got) != 0 {
+		t.Errorf("punctuation-only Tokenize = %q, want none", got)
+	}
+}
+
+func TestNGramWeightsFlipArgmax(t *testing.T) {
+	train := func(nb *NaiveBayesClassifier) {
+		nb.Train("bad", "negative")
+		nb.Train("bad service", "negative")
+		nb.Train("so bad", "negative")
+		nb.Train("bad food", "negative")
+		nb.Train("really bad", "negative")
+		nb.Train("not bad at all", "positive")
+		nb.Train("great", "positive")
+	}
+	tests := []struct {
+		bigramWeight float64
+		want         string
+	}{
+		{1, "negative"},
+		{4, "positive"},
+	}
+	for _, tt := range tests {
+		nb := NewNaiveBayesClassifier(NGrams(2), NGramWeights(map[int]float64{2: tt.bigramWeight}))
+		train(nb)
+		if got, probs := nb.Predict("not bad"); got != tt.want {
+			t.Errorf("bigram weight %v: Predict(not bad) = %s %v, want %s", tt.bigramWeight, got, probs, tt.want)
+		}
+
+		// Hashing turns "not bad" into a bucket name, but its weight is taken
+		// before that, so the hashed model must flip the same way.
+		hashed := NewNaiveBayesClassifier(NGrams(2), NGramWeights(map[int]float64{2: tt.bigramWeight}), FeatureHashing(1<<16))
+		train(hashed)
+		if got, probs := hashed.Predict("not bad"); got != tt.want {
+			t.Errorf("hashed, bigram weight %v: Predict(not bad) = %s %v, want %s", tt.bigramWeight, got, probs, tt.want)
+		}
+		if detailed := hashed.PredictDetailed("not bad"); detailed.Label != tt.want {
+			t.Errorf("hashed, bigram weight %v: PredictDetailed(not bad) = %s, want %s", tt.bigramWeight, detailed.Label, tt.want)
+		}
+	}
+}
+
+func TestFeatureWeightByOrder(t *testing.T) {
+	nb := NewNaiveBayesClassifier(NGrams(3), NGramWeights(map[int]float64{1: 0.5, 3: 2}))
+	tests := map[string]float64{
+		"bad": 0.5, "not bad": 1, "not so bad": 2,
+		// Non-n-gram features count as order 1.
+		"cooc_not_bad": 0.5, "bad@start": 0.5, sentenceBreakToken: 0.5,
+	}
+	for token, want := range tests {
+		if got := nb.featureWeight(token); got != want {
+			t.Errorf("featureWeight(%q) = %v, want %v", token, got, want)
+		}
+	}
+	if got := NewNaiveBayesClassifier().featureWeight("not bad"); got != 1 {
+		t.Errorf("default featureWeight = %v, want 1", got)
+	}
+}
+
+func TestStripHTML(t *testing.T) {
+	nb := NewNaiveBayesClassifier(StripHTML())
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Great<br>movie<br/>", []string{"great", "movie"}},
+		{"Fish &amp; chips &lt;3", []string{"fish", "chips", "3"}},
+		{`<p class="review">Loved <b>it</b> &mdash; really</p>`, []string{"loved", "it", "really"}},
+		{"plain text stays", []string{"plain", "text", "stays"}},
+	}
+	for _, tt := range tests {
+		if got := nb.Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	// Without the option tag names become tokens.
+	if got := NewNaiveBayesClassifier().Tokenize("Great<br>movie"); !reflect.DeepEqual(got, []string{"great", "br", "movie"}) {
+		t.Errorf("Tokenize without StripHTML = %q", got)
+	}
+}
+
+func TestStripHTMLMarkupNormalizesWhitespace(t *testing.T) {
+	if got := stripHTMLMarkup("one<br>two \n\t three&nbsp;four"); got != "one two three four" {
+		t.Errorf("stripHTMLMarkup = %q, want %q", got, "one two three four")
+	}
+}
+
+func TestTokenPatterns(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		text string
+		want []string
+	}{
+		{
+			name: "allow alphabetic only",
+			opts: []Option{TokenAllowPattern(regexp.MustCompile(`^[a-z]+$`))},
+			text: "Battery lasts 10 hours, 5 stars",
+			want: []string{"battery", "lasts", "hours", "stars"},
+		},
+		{
+			name: "deny numbers",
+			opts: []Option{TokenDenyPattern(regexp.MustCompile(`^[0-9]+$`))},
+			text: "Battery lasts 10 hours",
+			want: []string{"battery", "lasts", "hours"},
+		},
+		{
+			name: "deny whole URLs",
+			opts: []Option{TokenDenyPattern(regexp.MustCompile(`^https?://`))},
+			text: "Terrible, see https://example.com/review?id=3 for details",
+			want: []string{"terrible", "see", "for", "details"},
+		},
+		{
+			name: "allow and deny combined",
+			opts: []Option{
+				TokenAllowPattern(regexp.MustCompile(`^[a-z0-9]+$`)),
+				TokenDenyPattern(regexp.MustCompile(`^[0-9]+$`)),
+			},
+			text: "Top 10 phone",
+			want: []string{"top", "phone"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nb := NewNaiveBayesClassifier(tt.opts...)
+			if got := nb.Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenPatternsApplyToTrainAndPredict(t *testing.T) {
+	nb := NewNaiveBayesClassifier(TokenDenyPattern(regexp.MustCompile(`^[0-9]+$`)))
+	nb.Train("great phone 2024", "positive")
+	nb.Train("awful phone 2023", "negative")
+	for _, token := range []string{"2024", "2023"} {
+		if _, ok := nb.vocabulary[token]; ok {
+			t.Errorf("denied token %q entered the vocabulary", token)
+		}
+	}
+	prediction := nb.PredictDetailed("2024 2024 2024")
+	if prediction.TokenCount != 0 {
+		t.Errorf("Predict saw %d tokens of a numbers-only text, want 0", prediction.TokenCount)
+	}
+}
+
+func TestCollapseRepeatedChars(t *testing.T) {
+	tests := []struct {
+		token string
+		keep  int
+		want  string
+	}{
+		{"so", 2, "so"},
+		{"soo", 2, "soo"},
+		{"sooo", 2, "soo"},
+		{"soooooooo", 2, "soo"},
+		{"sooo", 1, "so"},
+		{"goooood", 1, "god"},
+		{"cool", 1, "cool"},
+		{"yessss!!!", 2, "yess!!"},
+		{"ääää", 2, "ää"},
+	}
+	for _, tt := range tests {
+		if got := collapseRepeats(tt.token, tt.keep); got != tt.want {
+			t.Errorf("collapseRepeats(%q, %d) = %q, want %q", tt.token, tt.keep, got, tt.want)
+		}
+	}
+}
+
+func TestCollapseRepeatedCharsSharesFeatures(t *testing.T) {
+	nb := NewNaiveBayesClassifier(CollapseRepeatedChars(2))
+	nb.Train("sooo goooood", "positive")
+	nb.Train("soooooo gooood", "positive")
+	if got := nb.classWordCounts["positive"]["soo"]; got != 2 {
+		t.Errorf("count of soo = %v, want 2 (both elongations collapsed)", got)
+	}
+	if got := nb.Tokenize("Soooooo Goooood"); !reflect.DeepEqual(got, []string{"soo", "good"}) {
+		t.Errorf("Tokenize = %q, want [soo good]", got)
+	}
+	for _, keep := range []int{0, 3} {
+		if got := NewNaiveBayesClassifier(CollapseRepeatedChars(keep)).Tokenize("sooo"); !reflect.DeepEqual(got, []string{"sooo"}) {
+			t.Errorf("CollapseRepeatedChars(%d) changed the token: %q", keep, got)
+		}
+	}
+}
+
+func TestFeatureHashing(t *testing.T) {
+	nb := NewNaiveBayesClassifier(FeatureHashing(64))
+	for i := 0; i < 200; i++ {
+		nb.Train(fmt.Sprintf("great loved it item%d", i), "positive")
+		nb.Train(fmt.Sprintf("awful hated it item%d", i+200), "negative")
+	}
+	if len(nb.vocabulary) > 64 {
+		t.Errorf("hashed vocabulary has %d entries, want at most 64 buckets", len(nb.vocabulary))
+	}
+	for token := range nb.vocabulary {
+		if !strings.HasPrefix(token, "#") {
+			t.Fatalf("vocabulary holds %q, want only bucket names", token)
+		}
+	}
+	for text, want := range map[string]string{"loved it, great": "positive", "hated it, awful": "negative"} {
+		if got, probs := nb.Predict(text); got != want {
+			t.Errorf("Predict(%q) = %s %v, want %s", text, got, probs, want)
+		}
+	}
+	if got := nb.Tokenize("great"); !reflect.DeepEqual(got, []string{hashFeature("great", 64)}) {
+		t.Errorf("Tokenize(great) = %v, want its bucket", got)
+	}
+}
+
+// BenchmarkTrainMemory trains on 2000 documents with 20000 distinct tokens
+// and reports the heap retained by the trained model.
+func BenchmarkTrainMemory(b *testing.B) {
+	docs := make([]Document, 2000)
+	for i := range docs {
+		words := make([]string, 10)
+		for j := range words {
+			words[j] = fmt.Sprintf("token%d", i*10+j)
+		}
+		docs[i] = Document{Text: strings.Join(words, " "), Label: []string{"positive", "negative"}[i%2]}
+	}
+	for _, tt := range []struct {
+		name string
+		opts []Option
+	}{
+		{"map", nil},
+		{"hashed-1024", []Option{FeatureHashing(1024)}},
+	} {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			var retained int64
+			for i := 0; i < b.N; i++ {
+				var before, after runtime.MemStats
+				runtime.GC()
+				runtime.ReadMemStats(&before)
+				nb := NewNaiveBayesClassifier(tt.opts...)
+				nb.TrainBatch(docs)
+				runtime.GC()
+				runtime.ReadMemStats(&after)
+				retained += int64(after.HeapAlloc) - int64(before.HeapAlloc)
+				runtime.KeepAlive(nb)
+			}
+			b.ReportMetric(float64(retained)/float64(b.N), "retained-B/op")
+		})
+	}
+}
+
+func TestSplitIdentifiers(t *testing.T) {
+	nb := NewNaiveBayesClassifier(SplitIdentifiers())
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"getUserName", []string{"get", "user", "name"}},
+		{"GetUserName", []string{"get", "user", "name"}},
+		{"get_user_name", []string{"get", "user", "name"}},
+		{"parseHTTPResponse", []string{"parse", "http", "response"}},
+		{"utf8Decoder", []string{"utf8", "decoder"}},
+		{"fix getUserName crash", []string{"fix", "get", "user", "name", "crash"}},
+	}
+	for _, tt := range tests {
+		if got := nb.Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+
+	if got := NewNaiveBayesClassifier().Tokenize("getUserName"); !reflect.DeepEqual(got, []string{"getusername"}) {
+		t.Errorf("without SplitIdentifiers Tokenize(getUserName) = %q, want one token", got)
+	}
+}
+
+func TestCoOccurrence(t *testing.T) {
+	nb := NewNaiveBayesClassifier(CoOccurrence(3))
+	got := nb.Tokenize("not one I recommend")
+	want := []string{
+		"not", "one", "i", "recommend",
+		"cooc_not_one", "cooc_not_i", "cooc_not_recommend",
+		"cooc_one_i", "cooc_one_recommend",
+		"cooc_i_recommend",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tokenize with window 3 = %q, want %q", got, want)
+	}
+
+	narrow := NewNaiveBayesClassifier(CoOccurrence(1)).Tokenize("not one I recommend")
+	for _, token := range narrow {
+		if token == "cooc_not_recommend" {
+			t.Error("window 1 paired not with recommend, three positions away")
+		}
+	}
+	if len(narrow) != 7 {
+		t.Errorf("Tokenize with window 1 = %q, want 4 words and 3 adjacent pairs", narrow)
+	}
+	if got := NewNaiveBayesClassifier().Tokenize("not one I recommend"); len(got) != 4 {
+		t.Errorf("without CoOccurrence Tokenize = %q, want only the words", got)
+	}
+}
+
+func TestSentenceBreaks(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"Great! Loved it! Wow!", 3},
+		{"One long sentence about a decent phone.", 1},
+		{"Really?! No way...", 2},
+		{"no punctuation at all", 0},
+		{"Mr. Smith rated it 3.5 stars.", 3},
+	}
+	nb := NewNaiveBayesClassifier(SentenceBreaks())
+	for _, tt := range tests {
+		breaks := 0
+		for _, token := range nb.Tokenize(tt.text) {
+			if token == sentenceBreakToken {
+				breaks++
+			}
+		}
+		if breaks != tt.want {
+			t.Errorf("Tokenize(%q) has %d %s tokens, want %d", tt.text, breaks, sentenceBreakToken, tt.want)
+		}
+	}
+
+	nb.Train("Great! Loved it! Wow!", "positive")
+	nb.Train("It broke after a week.", "negative")
+	if nb.classWordCounts["positive"][sentenceBreakToken] != 3 || nb.classWordCounts["negative"][sentenceBreakToken] != 1 {
+		t.Errorf("sent_break counts = %v positive, %v negative; want 3 and 1",
+			nb.classWordCounts["positive"][sentenceBreakToken], nb.classWordCounts["negative"][sentenceBreakToken])
+	}
+	for _, token := range NewNaiveBayesClassifier().Tokenize("Great! Loved it!") {
+		if token == sentenceBreakToken {
+			t.Error("sent_break emitted without SentenceBreaks")
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package sentiment
+
+// GroupCounts holds the training counts of several classes added together, as
+// if they had been trained as a single class.
+type GroupCounts struct {
+	Labels     []string
+	Documents  float64
+	Words      map[string]float64
+	TotalWords float64
+}
+
+// LabelGroupCounts sums the document and word counts of the given labels, for
+// example several negative-leaning classes, so collapsed label schemes can be
+// examined without retraining. Labels the model does not know contribute
+// nothing; a label listed twice is counted once.
+func (nb *NaiveBayesClassifier) LabelGroupCounts(labels ...string) GroupCounts {
+	group := GroupCounts{Words: make(map[string]float64)}
+	seen := make(map[string]struct{}, len(labels))
+	for _, label := range labels {
+		if _, dup := seen[label]; dup {
+			continue
+		}
+		seen[label] = struct{}{}
+		group.Labels = append(group.Labels, label)
+		group.Documents += nb.classDocCounts[label]
+		group.TotalWords += nb.classTotalWords[label]
+		for token, count := range nb.classWordCounts[label] {
+			group.Words[token] += count
+		}
+	}
+	return group
+}
@@ -0,0 +1,29 @@
+package sentiment
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLabelGroupCounts(t *testing.T) {
+	nb := NewNaiveBayesClassifier()
+	nb.Train("awful awful service", "angry")
+	nb.Train("slow service", "disappointed")
+	nb.Train("slow delivery", "disappointed")
+	nb.Train("great service", "happy")
+
+	got := nb.LabelGroupCounts("angry", "disappointed", "angry", "unknown")
+	want := GroupCounts{
+		Labels:     []string{"angry", "disappointed", "unknown"},
+		Documents:  3,
+		Words:      map[string]float64{"awful": 2, "service": 2, "slow": 2, "delivery": 1},
+		TotalWords: 7,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LabelGroupCounts = %+v, want %+v", got, want)
+	}
+
+	if empty := nb.LabelGroupCounts(); empty.Documents != 0 || len(empty.Words) != 0 {
+		t.Errorf("empty group = %+v, want no counts", empty)
+	}
+}
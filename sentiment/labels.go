@@ -0,0 +1,26 @@
+package sentiment
+
+import "strings"
+
+// NormalizeLabel is the default label canonicalization: surrounding
+// whitespace is trimmed and the label is lowercased, so "Positive" and
+// " positive" name the same class. The dataset loaders apply it too.
+func NormalizeLabel(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
+
+// LabelNormalizer replaces NormalizeLabel as the canonicalization Train and
+// the evaluation helpers apply to labels. A nil fn keeps labels exactly as
+// given.
+func LabelNormalizer(fn func(string) string) Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.labelNormalizer = fn
+	}
+}
+
+func (nb *NaiveBayesClassifier) canonicalLabel(label string) string {
+	if nb.labelNormalizer == nil {
+		return label
+	}
+	return nb.labelNormalizer(label)
+}
@@ -0,0 +1,32 @@
+package sentiment
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLabelNormalizer(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want map[string]float64
+	}{
+		{"default lowercases", nil, map[string]float64{"positive": 2}},
+		{"nil keeps labels", []Option{LabelNormalizer(nil)}, map[string]float64{"Positive": 1, "positive": 1}},
+		{"custom", []Option{LabelNormalizer(strings.ToUpper)}, map[string]float64{"POSITIVE": 2}},
+	}
+	for _, tt := range tests {
+		nb := NewNaiveBayesClassifier(tt.opts...)
+		nb.Train("good", "Positive")
+		nb.Train("great", "positive")
+		if len(nb.classDocCounts) != len(tt.want) {
+			t.Errorf("%s: classes = %v, want %v", tt.name, nb.classDocCounts, tt.want)
+			continue
+		}
+		for class, count := range tt.want {
+			if nb.classDocCounts[class] != count {
+				t.Errorf("%s: classes = %v, want %v", tt.name, nb.classDocCounts, tt.want)
+			}
+		}
+	}
+}
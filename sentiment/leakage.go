@@ -0,0 +1,60 @@
+package sentiment
+
+import (
+	"math"
+	"sort"
+)
+
+// LeakageSuspect is a token whose training occurrences are concentrated in a
+// single class.
+type LeakageSuspect struct {
+	Token string
+	// Class is the class holding most of the token's occurrences.
+	Class string
+	// Count is the token's total count across classes.
+	Count float64
+	// Entropy is the entropy, in bits, of the token's distribution over
+	// classes; 0 means it never occurred outside Class.
+	Entropy float64
+}
+
+// LeakageSuspects flags tokens that may encode the label in the text, such as
+// a category name or a template marker left in the data: tokens counted at
+// least minCount times in total whose distribution over classes has an
+// entropy of at most maxEntropy bits. A maxEntropy of 0 keeps only tokens
+// seen in a single class. Suspects are sorted by count, highest first, with
+// ties broken alphabetically.
+func (nb *NaiveBayesClassifier) LeakageSuspects(minCount, maxEntropy float64) []LeakageSuspect {
+	var suspects []LeakageSuspect
+	for token := range nb.vocabulary {
+		var total, best float64
+		bestClass := ""
+		for class, counts := range nb.classWordCounts {
+			count := counts[token]
+			total += count
+			if count > best || (count == best && count > 0 && class < bestClass) {
+				best, bestClass = count, class
+			}
+		}
+		if total <= 0 || total < minCount {
+			continue
+		}
+		var entropy float64
+		for _, counts := range nb.classWordCounts {
+			if p := counts[token] / total; p > 0 {
+				entropy -= p * math.Log2(p)
+			}
+		}
+		if entropy > maxEntropy {
+			continue
+		}
+		suspects = append(suspects, LeakageSuspect{Token: token, Class: bestClass, Count: total, Entropy: entropy})
+	}
+	sort.Slice(suspects, func(i, j int) bool {
+		if suspects[i].Count != suspects[j].Count {
+			return suspects[i].Count > suspects[j].Count
+		}
+		return suspects[i].Token < suspects[j].Token
+	})
+	return suspects
+}
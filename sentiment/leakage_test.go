@@ -0,0 +1,49 @@
+package sentiment
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLeakageSuspects(t *testing.T) {
+	nb := NewNaiveBayesClassifier()
+	// Every positive review carries the template marker "posreview"; the
+	// other words occur in both classes.
+	for i := 0; i < 6; i++ {
+		nb.Train(fmt.Sprintf("posreview the food was fine %d", i), "positive")
+		nb.Train(fmt.Sprintf("the food was fine %d", i), "negative")
+	}
+	nb.Train("food was cold", "negative")
+
+	suspects := nb.LeakageSuspects(5, 0)
+	if len(suspects) != 1 {
+		t.Fatalf("suspects = %+v, want only the planted token", suspects)
+	}
+	want := LeakageSuspect{Token: "posreview", Class: "positive", Count: 6, Entropy: 0}
+	if suspects[0] != want {
+		t.Errorf("suspect = %+v, want %+v", suspects[0], want)
+	}
+
+	// "cold" is exclusive to negative but too rare to flag at minCount 5.
+	suspects = nb.LeakageSuspects(1, 0)
+	if len(suspects) != 2 || suspects[1].Token != "cold" {
+		t.Errorf("suspects at minCount 1 = %+v, want posreview then cold", suspects)
+	}
+
+	// "food" splits 6:7, an entropy just under 1 bit.
+	found := false
+	for _, s := range nb.LeakageSuspects(5, 1) {
+		if s.Token == "food" {
+			found = true
+			if s.Class != "negative" || s.Entropy < 0.99 || s.Entropy >= 1 {
+				t.Errorf("food = %+v, want negative with an entropy just under 1", s)
+			}
+		}
+	}
+	if !found {
+		t.Error("food is missing at maxEntropy 1")
+	}
+	if got := nb.LeakageSuspects(5, 0.9); len(got) != 1 {
+		t.Errorf("suspects at maxEntropy 0.9 = %+v, want only posreview", got)
+	}
+}
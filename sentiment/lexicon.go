@@ -0,0 +1,64 @@
+package sentiment
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// TokenPolarity scores how strongly a vocabulary token separates the two
+// classes of a binary model.
+type TokenPolarity struct {
+	Token string
+	// LogRatio is log(P(token|first) / P(token|second)) where the classes are
+	// taken in alphabetical order; positive values favour the first class.
+	LogRatio float64
+	// Score is the absolute value of LogRatio.
+	Score float64
+}
+
+// DecisionBoundaryTokens scores every vocabulary token of a binary classifier
+// by the absolute log-ratio of its smoothed class probabilities. It returns up
+// to n of the most neutral tokens (scores closest to zero) and up to n of the
+// most polarized tokens (largest scores). Ties are broken alphabetically.
+func (nb *NaiveBayesClassifier) DecisionBoundaryTokens(n int) (neutral, polarized []TokenPolarity, err error) {
+	classes := make([]string, 0, len(nb.classDocCounts))
+	for class, count := range nb.classDocCounts {
+		if count > 0 {
+			classes = append(classes, class)
+		}
+	}
+	if len(classes) != 2 {
+		return nil, nil, fmt.Errorf("decision boundary tokens require exactly 2 classes, model has %d", len(classes))
+	}
+	sort.Strings(classes)
+
+	scored := make([]TokenPolarity, 0, len(nb.vocabulary))
+	for token := range nb.vocabulary {
+		ratio := math.Log(nb.wordProbability(classes[0], token) / nb.wordProbability(classes[1], token))
+		scored = append(scored, TokenPolarity{Token: token, LogRatio: ratio, Score: math.Abs(ratio)})
+	}
+	if n < 0 {
+		n = 0
+	}
+	if n > len(scored) {
+		n = len(scored)
+	}
+
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].Score != scored[j].Score {
+			return scored[i].Score < scored[j].Score
+		}
+		return scored[i].Token < scored[j].Token
+	})
+	neutral = append([]TokenPolarity(nil), scored[:n]...)
+
+	sort.Slice(scored, func(i, j int) bool {
+		if scored[i].Score != scored[j].Score {
+			return scored[i].Score > scored[j].Score
+		}
+		return scored[i].Token < scored[j].Token
+	})
+	polarized = append([]TokenPolarity(nil), scored[:n]...)
+	return neutral, polarized, nil
+}
@@ -0,0 +1,46 @@
+package sentiment
+
+// Rough per-entry costs of the Go maps the model is built from: a 16-byte
+// string header, the value, and an allowance for hash-bucket overhead and
+// spare capacity. Key bytes are added on top.
+const (
+	vocabEntryOverhead = 16 + 16
+	countEntryOverhead = 16 + 8 + 16
+)
+
+// MemoryStats is an approximate breakdown of the memory held by a model.
+type MemoryStats struct {
+	Classes           int
+	VocabularyEntries int
+	WordCountEntries  int
+	VocabularyBytes   int
+	WordCountBytes    int
+	TotalBytes        int
+}
+
+// MemoryStats estimates how much memory the vocabulary and per-class count
+// maps use, from their sizes and key lengths. The byte figures are rough and
+// meant for capacity planning, such as deciding when to cap the vocabulary
+// or switch to FeatureHashing; the entry counts are exact.
+func (nb *NaiveBayesClassifier) MemoryStats() MemoryStats {
+	stats := MemoryStats{
+		Classes:           len(nb.classDocCounts),
+		VocabularyEntries: len(nb.vocabulary),
+	}
+	for token := range nb.vocabulary {
+		stats.VocabularyBytes += vocabEntryOverhead + len(token)
+	}
+	for class, counts := range nb.classWordCounts {
+		stats.WordCountEntries += len(counts)
+		stats.WordCountBytes += countEntryOverhead + len(class)
+		for token := range counts {
+			stats.WordCountBytes += countEntryOverhead + len(token)
+		}
+	}
+	// classDocCounts, classTotalWords and classSingletons hold one entry per class.
+	for class := range nb.classDocCounts {
+		stats.WordCountBytes += 3 * (countEntryOverhead + len(class))
+	}
+	stats.TotalBytes = stats.VocabularyBytes + stats.WordCountBytes
+	return stats
+}
@@ -0,0 +1,31 @@
+package sentiment
+
+import "testing"
+
+func TestMemoryStatsCountsMatchMaps(t *testing.T) {
+	nb := NewNaiveBayesClassifier()
+	nb.TrainBatch(DefaultDataset())
+
+	stats := nb.MemoryStats()
+	wordCounts := 0
+	for _, counts := range nb.classWordCounts {
+		wordCounts += len(counts)
+	}
+	if stats.Classes != len(nb.classDocCounts) || stats.VocabularyEntries != len(nb.vocabulary) || stats.WordCountEntries != wordCounts {
+		t.Errorf("MemoryStats = %+v, want %d classes, %d vocabulary and %d word count entries",
+			stats, len(nb.classDocCounts), len(nb.vocabulary), wordCounts)
+	}
+	if stats.VocabularyBytes <= 0 || stats.TotalBytes != stats.VocabularyBytes+stats.WordCountBytes {
+		t.Errorf("MemoryStats bytes = %+v, want positive parts adding up to the total", stats)
+	}
+
+	nb.Train("completely novel words", "neutral")
+	grown := nb.MemoryStats()
+	if grown.Classes != stats.Classes+1 || grown.VocabularyEntries != stats.VocabularyEntries+3 || grown.TotalBytes <= stats.TotalBytes {
+		t.Errorf("after training a new class and 3 new words MemoryStats = %+v, was %+v", grown, stats)
+	}
+
+	if empty := NewNaiveBayesClassifier().MemoryStats(); empty != (MemoryStats{}) {
+		t.Errorf("untrained MemoryStats = %+v, want zero", empty)
+	}
+}
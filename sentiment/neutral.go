@@ -0,0 +1,39 @@
+package sentiment
+
+import "math"
+
+// NeutralBand wraps a binary classifier and reports NeutralLabel whenever the
+// winning probability lies within Band of 0.5, synthesizing a third outcome
+// from a model trained only on two classes.
+type NeutralBand struct {
+	Model        *NaiveBayesClassifier
+	Band         float64
+	NeutralLabel string
+}
+
+// NewNeutralBand returns a wrapper that labels predictions "neutral" when the
+// top probability is within band of 0.5.
+func NewNeutralBand(model *NaiveBayesClassifier, band float64) *NeutralBand {
+	return &NeutralBand{Model: model, Band: band, NeutralLabel: "neutral"}
+}
+
+// Predict classifies text with the wrapped model. When Band is positive and
+// |P(top) - 0.5| <= Band (edges inclusive) the label is replaced by
+// NeutralLabel. The probabilities are the wrapped model's, unchanged.
+func (n *NeutralBand) Predict(text string) (string, map[string]float64) {
+	label, probs := n.Model.Predict(text)
+	return n.Apply(label, probs), probs
+}
+
+// Apply returns the label Predict would report for a prediction the wrapped
+// model has already made, which lets the band be combined with other
+// wrappers such as CachedClassifier.
+func (n *NeutralBand) Apply(label string, probs map[string]float64) string {
+	if n.Band <= 0 || label == "" {
+		return label
+	}
+	if math.Abs(probs[label]-0.5) <= n.Band {
+		return n.NeutralLabel
+	}
+	return label
+}
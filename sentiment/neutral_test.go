@@ -0,0 +1,49 @@
+package sentiment
+
+import "testing"
+
+func TestNeutralBandEdges(t *testing.T) {
+	tests := []struct {
+		band float64
+		top  float64
+		want string
+	}{
+		{0.1, 0.5, "neutral"},
+		{0.1, 0.55, "neutral"},
+		{0.1, 0.6, "neutral"}, // the edge is inclusive
+		{0.1, 0.61, "positive"},
+		{0.1, 0.99, "positive"},
+		{0, 0.5, "positive"}, // a zero band disables the wrapper
+		{0.5, 1, "neutral"},
+	}
+	for _, tt := range tests {
+		n := &NeutralBand{Band: tt.band, NeutralLabel: "neutral"}
+		probs := map[string]float64{"positive": tt.top, "negative": 1 - tt.top}
+		if got := n.Apply("positive", probs); got != tt.want {
+			t.Errorf("band %v, P(top) = %v: got %s, want %s", tt.band, tt.top, got, tt.want)
+		}
+	}
+}
+
+func TestNeutralBandPredict(t *testing.T) {
+	nb := trainTiny()
+	label, probs := nb.Predict("good")
+	if label != "positive" {
+		t.Fatalf("Predict(good) = %s %v, want positive", label, probs)
+	}
+	margin := probs[label] - 0.5
+
+	n := NewNeutralBand(nb, margin+0.01)
+	if got, gotProbs := n.Predict("good"); got != "neutral" || gotProbs[label] != probs[label] {
+		t.Errorf("band above the margin: got %s %v, want neutral with unchanged probabilities", got, gotProbs)
+	}
+	n.Band = margin - 0.01
+	if got, _ := n.Predict("good"); got != "positive" {
+		t.Errorf("band below the margin: got %s, want positive", got)
+	}
+
+	untrained := NewNeutralBand(NewNaiveBayesClassifier(), 0.5)
+	if got, _ := untrained.Predict("good"); got != "" {
+		t.Errorf("untrained model: got %q, want the empty label", got)
+	}
+}
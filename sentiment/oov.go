@@ -0,0 +1,69 @@
+package sentiment
+
+// GoodTuringOOV replaces flat additive smoothing with a per-class estimate of
+// the unseen-token probability. Following Good-Turing, the probability mass
+// reserved for tokens a class has never seen is N1/N, where N1 is the number of
+// tokens seen exactly once in that class and N its total word count. The mass
+// is shared evenly among the vocabulary tokens missing from the class plus one
+// slot for tokens outside the vocabulary, and seen tokens are discounted to
+// (1 - N1/N) * count/N. Classes with diverse vocabularies (many once-seen
+// words) therefore assign more probability to unknown words.
+func GoodTuringOOV() Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.goodTuringOOV = true
+	}
+}
+
+// isSingleton reports whether a stored count corresponds to a token seen once.
+// Counts below one can occur when term frequencies are rescaled.
+func isSingleton(count float64) bool {
+	return count > 0 && count <= 1
+}
+
+// updateSingletons keeps the per-class once-seen token counts current as a
+// token's count changes from before to after.
+func (nb *NaiveBayesClassifier) updateSingletons(class string, before, after float64) {
+	if isSingleton(before) {
+		nb.classSingletons[class]--
+	}
+	if isSingleton(after) {
+		nb.classSingletons[class]++
+	}
+}
+
+// recountSingletons rebuilds the once-seen token counts from the word counts.
+func (nb *NaiveBayesClassifier) recountSingletons() {
+	nb.classSingletons = make(map[string]int, len(nb.classWordCounts))
+	for class, counts := range nb.classWordCounts {
+		for _, count := range counts {
+			if isSingleton(count) {
+				nb.classSingletons[class]++
+			}
+		}
+	}
+}
+
+// goodTuringProbability returns the Good-Turing estimate of P(token|class). The
+// boolean is false when the class has no words to estimate from.
+func (nb *NaiveBayesClassifier) goodTuringProbability(class, token string) (float64, bool) {
+	total := nb.classTotalWords[class]
+	if total <= 0 {
+		return 0, false
+	}
+	unseenMass := float64(nb.classSingletons[class]) / total
+	if unseenMass <= 0 {
+		unseenMass = 1 / (total + 1)
+	}
+	if unseenMass >= 1 {
+		unseenMass = total / (total + 1)
+	}
+
+	if count := nb.classWordCounts[class][token]; count > 0 {
+		return (1 - unseenMass) * count / total, true
+	}
+	unseenTypes := len(nb.vocabulary) - len(nb.classWordCounts[class]) + 1
+	if unseenTypes < 1 {
+		unseenTypes = 1
+	}
+	return unseenMass / float64(unseenTypes), true
+}
@@ -0,0 +1,58 @@
+package sentiment
+
+import "testing"
+
+// trainDiversity trains a class of four once-seen words and a class that
+// repeats a single word four times, over the vocabulary {a, b, c, d, x}.
+func trainDiversity(opts ...Option) *NaiveBayesClassifier {
+	nb := NewNaiveBayesClassifier(opts...)
+	nb.Train("a b c d", "diverse")
+	nb.Train("x x x x", "repetitive")
+	return nb
+}
+
+func TestGoodTuringOOVVersusFlatSmoothing(t *testing.T) {
+	flat := trainDiversity().WordClassProbabilities("unseen")
+	if !approxEqual(flat["diverse"], flat["repetitive"]) {
+		t.Errorf("flat smoothing OOV probabilities = %v, want equal for equally sized classes", flat)
+	}
+
+	gt := trainDiversity(GoodTuringOOV()).WordClassProbabilities("unseen")
+	// diverse: N1/N = 4/4 is capped at N/(N+1) = 0.8, shared by x and the OOV slot.
+	// repetitive: no singletons, so the mass is 1/(N+1) = 0.2 over a, b, c, d and the OOV slot.
+	want := map[string]float64{"diverse": 0.4, "repetitive": 0.04}
+	for class, p := range want {
+		if !approxEqual(gt[class], p) {
+			t.Errorf("Good-Turing P(unseen|%s) = %v, want %v", class, gt[class], p)
+		}
+	}
+	if gt["diverse"] <= gt["repetitive"] {
+		t.Errorf("the diverse class should reserve more mass for unknown words: %v", gt)
+	}
+}
+
+func TestGoodTuringOOVIsADistribution(t *testing.T) {
+	nb := trainDiversity(GoodTuringOOV())
+	for _, class := range []string{"diverse", "repetitive"} {
+		sum := nb.WordClassProbabilities("unseen")[class] // the out-of-vocabulary slot
+		for _, entry := range nb.Vocabulary() {
+			sum += nb.WordClassProbabilities(entry.Token)[class]
+		}
+		if !approxEqual(sum, 1) {
+			t.Errorf("class %s probabilities sum to %v, want 1", class, sum)
+		}
+	}
+}
+
+func TestGoodTuringOOVSingletonsTrackCounts(t *testing.T) {
+	nb := trainDiversity(GoodTuringOOV())
+	nb.Train("a", "diverse")
+	if got := nb.classSingletons["diverse"]; got != 3 {
+		t.Errorf("singletons after seeing a twice = %d, want 3", got)
+	}
+	loaded := NewNaiveBayesClassifier()
+	loaded.LoadSnapshot(nb.Snapshot())
+	if got := loaded.classSingletons["diverse"]; got != 3 {
+		t.Errorf("singletons after LoadSnapshot = %d, want 3", got)
+	}
+}
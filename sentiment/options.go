@@ -0,0 +1,107 @@
+package sentiment
+
+import "regexp"
+
+// SnapshotOptions records the settings that change how a model extracts
+// features, counts them or scores them, so a model loaded from a snapshot
+// behaves like the one that was saved. Smoothing lives in the snapshot's own
+// Smoothing fields. Label normalizers and masked tokens are not persisted.
+type SnapshotOptions struct {
+	TermFrequency    TermFrequencyMode `json:"term_frequency,omitempty"`
+	PriorWeight      float64           `json:"prior_weight"`
+	DefaultLabel     string            `json:"default_label,omitempty"`
+	PositionFeatures bool              `json:"position_features,omitempty"`
+	MaxVocabSize     int               `json:"max_vocab_size,omitempty"`
+	MinDocFrequency  int               `json:"min_doc_frequency,omitempty"`
+	GoodTuringOOV    bool              `json:"good_turing_oov,omitempty"`
+	MaxNGram         int               `json:"max_ngram,omitempty"`
+	NGramWeights     map[int]float64   `json:"ngram_weights,omitempty"`
+	StripHTML        bool              `json:"strip_html,omitempty"`
+	TokenAllow       string            `json:"token_allow,omitempty"`
+	TokenDeny        string            `json:"token_deny,omitempty"`
+	CollapseRepeats  int               `json:"collapse_repeats,omitempty"`
+	DecayFactor      float64           `json:"decay_factor,omitempty"`
+	VocabFrozen      bool              `json:"vocab_frozen,omitempty"`
+	HashBuckets      int               `json:"hash_buckets,omitempty"`
+	SplitIdentifiers bool              `json:"split_identifiers,omitempty"`
+	CoOccurrence     int               `json:"cooccurrence_window,omitempty"`
+	MaxTokenRepeats  int               `json:"max_token_repeats,omitempty"`
+	SentenceBreaks   bool              `json:"sentence_breaks,omitempty"`
+}
+
+func (nb *NaiveBayesClassifier) snapshotOptions() *SnapshotOptions {
+	opts := &SnapshotOptions{
+		TermFrequency:    nb.tfMode,
+		PriorWeight:      nb.priorWeight,
+		DefaultLabel:     nb.defaultLabel,
+		PositionFeatures: nb.positionFeatures,
+		MaxVocabSize:     nb.maxVocabSize,
+		MinDocFrequency:  nb.minDocFreq,
+		GoodTuringOOV:    nb.goodTuringOOV,
+		MaxNGram:         nb.maxNGram,
+		StripHTML:        nb.stripHTML,
+		CollapseRepeats:  nb.collapseRepeats,
+		DecayFactor:      nb.decayFactor,
+		VocabFrozen:      nb.vocabFrozen,
+		HashBuckets:      nb.hashBuckets,
+		SplitIdentifiers: nb.splitIdentifiers,
+		CoOccurrence:     nb.coocWindow,
+		MaxTokenRepeats:  nb.maxTokenRepeats,
+		SentenceBreaks:   nb.sentenceBreaks,
+	}
+	if len(nb.ngramWeights) > 0 {
+		opts.NGramWeights = make(map[int]float64, len(nb.ngramWeights))
+		for order, weight := range nb.ngramWeights {
+			opts.NGramWeights[order] = weight
+		}
+	}
+	if nb.tokenAllow != nil {
+		opts.TokenAllow = nb.tokenAllow.String()
+	}
+	if nb.tokenDeny != nil {
+		opts.TokenDeny = nb.tokenDeny.String()
+	}
+	return opts
+}
+
+// applySnapshotOptions restores saved settings. Token patterns that no longer
+// compile are dropped rather than failing the load.
+func (nb *NaiveBayesClassifier) applySnapshotOptions(opts *SnapshotOptions) {
+	nb.tfMode = opts.TermFrequency
+	nb.priorWeight = opts.PriorWeight
+	nb.defaultLabel = opts.DefaultLabel
+	nb.positionFeatures = opts.PositionFeatures
+	nb.maxVocabSize = opts.MaxVocabSize
+	nb.minDocFreq = opts.MinDocFrequency
+	nb.goodTuringOOV = opts.GoodTuringOOV
+	nb.maxNGram = opts.MaxNGram
+	nb.ngramWeights = nil
+	if len(opts.NGramWeights) > 0 {
+		nb.ngramWeights = make(map[int]float64, len(opts.NGramWeights))
+		for order, weight := range opts.NGramWeights {
+			nb.ngramWeights[order] = weight
+		}
+	}
+	nb.stripHTML = opts.StripHTML
+	nb.tokenAllow = compileOptional(opts.TokenAllow)
+	nb.tokenDeny = compileOptional(opts.TokenDeny)
+	nb.collapseRepeats = opts.CollapseRepeats
+	nb.decayFactor = opts.DecayFactor
+	nb.vocabFrozen = opts.VocabFrozen
+	nb.hashBuckets = opts.HashBuckets
+	nb.splitIdentifiers = opts.SplitIdentifiers
+	nb.coocWindow = opts.CoOccurrence
+	nb.maxTokenRepeats = opts.MaxTokenRepeats
+	nb.sentenceBreaks = opts.SentenceBreaks
+}
+
+func compileOptional(pattern string) *regexp.Regexp {
+	if pattern == "" {
+		return nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil
+	}
+	return re
+}
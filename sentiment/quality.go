@@ -0,0 +1,57 @@
+package sentiment
+
+import "math"
+
+// Perplexity returns the per-token perplexity of docs under the model: the
+// exponentiated negative average log-likelihood of each token given the class
+// predicted for its document. Lower is better. Documents without tokens are
+// skipped, and 0 is returned when no tokens remain.
+func Perplexity(nb *NaiveBayesClassifier, docs []Document) float64 {
+	var logLikelihood float64
+	tokenCount := 0
+
+	for _, doc := range docs {
+		tokens := nb.extractFeatures(doc.Text)
+		if len(tokens) == 0 {
+			continue
+		}
+		predicted, _ := nb.Predict(doc.Text)
+		if predicted == "" {
+			continue
+		}
+		for _, token := range tokens {
+			if token == "" {
+				continue
+			}
+			logLikelihood += math.Log(nb.wordProbability(predicted, token))
+			tokenCount++
+		}
+	}
+
+	if tokenCount == 0 {
+		return 0
+	}
+	return math.Exp(-logLikelihood / float64(tokenCount))
+}
+
+// logLossEpsilon bounds the probabilities LogLoss takes the logarithm of, so
+// a confidently wrong prediction costs -log(1e-15) ≈ 34.5 instead of +Inf.
+const logLossEpsilon = 1e-15
+
+// LogLoss returns the average cross-entropy between the model's predicted
+// distribution and the true labels: the mean of -log P(actual|doc), with each
+// probability clamped to [1e-15, 1-1e-15]. A true label the model has never
+// seen counts as probability 0. Lower is better; 0 is returned for an empty
+// slice.
+func LogLoss(nb *NaiveBayesClassifier, docs []Document) float64 {
+	if len(docs) == 0 {
+		return 0
+	}
+	var total float64
+	for _, doc := range docs {
+		_, probs := nb.Predict(doc.Text)
+		p := math.Min(math.Max(probs[nb.canonicalLabel(doc.Label)], logLossEpsilon), 1-logLossEpsilon)
+		total -= math.Log(p)
+	}
+	return total / float64(len(docs))
+}
@@ -0,0 +1,29 @@
+package sentiment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLogLoss(t *testing.T) {
+	nb := trainTiny()
+	// With equal priors, P(positive|good) = 0.5 / (0.5 + 0.4) = 5/9 and
+	// P(negative|bad) = (2/5) / (1/6 + 2/5) = 12/17.
+	docs := []Document{
+		{Text: "good", Label: "positive"},
+		{Text: "bad", Label: "Negative"},
+	}
+	want := -(math.Log(5.0/9) + math.Log(12.0/17)) / 2
+	if got := LogLoss(nb, docs); !approxEqual(got, want) {
+		t.Errorf("LogLoss = %v, want %v", got, want)
+	}
+
+	// An unseen true label is clamped to 1e-15 rather than producing +Inf.
+	unseen := []Document{{Text: "good", Label: "neutral"}}
+	if got := LogLoss(nb, unseen); !approxEqual(got, -math.Log(logLossEpsilon)) {
+		t.Errorf("LogLoss with an unseen label = %v, want %v", got, -math.Log(logLossEpsilon))
+	}
+	if got := LogLoss(nb, nil); got != 0 {
+		t.Errorf("LogLoss(nil) = %v, want 0", got)
+	}
+}
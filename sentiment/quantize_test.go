@@ -0,0 +1,93 @@
+package sentiment
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// syntheticReviews returns n documents whose words are drawn from a shared
+// vocabulary, with lower-numbered words more likely in positive documents.
+func syntheticReviews(n int, seed int64) []Document {
+	rng := rand.New(rand.NewSource(seed))
+	docs := make([]Document, n)
+	for i := range docs {
+		label := "negative"
+		if i%2 == 0 {
+			label = "positive"
+		}
+		words := make([]string, 12)
+		for j := range words {
+			w := rng.Intn(200)
+			if label == "positive" && rng.Intn(3) == 0 {
+				w /= 4
+			}
+			words[j] = fmt.Sprintf("w%d", w)
+		}
+		docs[i] = Document{Text: strings.Join(words, " "), Label: label}
+	}
+	return docs
+}
+
+// gzipSnapshotSize returns the gzip-compressed size of the JSON snapshot.
+func gzipSnapshotSize(t *testing.T, nb *NaiveBayesClassifier) int {
+	t.Helper()
+	payload, err := json.Marshal(nb.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Len()
+}
+
+func TestQuantize(t *testing.T) {
+	nb := NewNaiveBayesClassifier()
+	nb.TrainBatch(syntheticReviews(2000, 1))
+	held := syntheticReviews(300, 2)
+	before := make([]string, len(held))
+	for i, doc := range held {
+		before[i], _ = nb.Predict(doc.Text)
+	}
+	sizeBefore := gzipSnapshotSize(t, nb)
+
+	nb.Quantize(10)
+	for class, count := range nb.classDocCounts {
+		if count != 100 {
+			t.Errorf("%s has %v documents after Quantize(10), want 100", class, count)
+		}
+	}
+	agree := 0
+	for i, doc := range held {
+		if label, _ := nb.Predict(doc.Text); label == before[i] {
+			agree++
+		}
+	}
+	if rate := float64(agree) / float64(len(held)); rate < 0.9 {
+		t.Errorf("%d of %d predictions unchanged (%.2f), want at least 90%%", agree, len(held), rate)
+	}
+	if sizeAfter := gzipSnapshotSize(t, nb); sizeAfter >= sizeBefore {
+		t.Errorf("gzipped snapshot grew from %d to %d bytes", sizeBefore, sizeAfter)
+	} else {
+		t.Logf("gzipped snapshot: %d -> %d bytes, %d/%d predictions unchanged", sizeBefore, sizeAfter, agree, len(held))
+	}
+}
+
+func TestQuantizeSmallScale(t *testing.T) {
+	nb := trainTiny()
+	want := nb.Fingerprint()
+	nb.Quantize(1)
+	if nb.Fingerprint() != want {
+		t.Error("Quantize(1) changed the model")
+	}
+}
@@ -0,0 +1,118 @@
+package sentiment
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ClassificationReport formats per-class precision, recall, F1 and support
+// plus accuracy, macro and weighted averages, mirroring the layout of
+// scikit-learn's classification_report.
+func ClassificationReport(m Metrics) string {
+	return ClassificationReportOrdered(m, nil)
+}
+
+// ClassificationReportOrdered is ClassificationReport with the class rows
+// arranged by OrderLabels(m.Labels(), order).
+func ClassificationReportOrdered(m Metrics, order []string) string {
+	labels := OrderLabels(m.Labels(), order)
+	width := len("weighted avg")
+	for _, label := range labels {
+		if len(label) > width {
+			width = len(label)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%*s %9s %9s %9s %9s\n\n", width, "", "precision", "recall", "f1-score", "support")
+
+	var macroP, macroR, macroF, weightedP, weightedR, weightedF float64
+	for _, label := range labels {
+		p, r, f := m.Precision(label), m.Recall(label), m.F1(label)
+		support := m.Support(label)
+		fmt.Fprintf(&b, "%*s %9.2f %9.2f %9.2f %9d\n", width, label, p, r, f, support)
+
+		macroP += p
+		macroR += r
+		macroF += f
+		weightedP += p * float64(support)
+		weightedR += r * float64(support)
+		weightedF += f * float64(support)
+	}
+	b.WriteString("\n")
+
+	fmt.Fprintf(&b, "%*s %9s %9s %9.2f %9d\n", width, "accuracy", "", "", m.Accuracy(), m.Total)
+	fmt.Fprintf(&b, "%*s %9s %9s %9.2f %9d\n", width, "kappa", "", "", CohensKappa(m), m.Total)
+	if n := float64(len(labels)); n > 0 {
+		fmt.Fprintf(&b, "%*s %9.2f %9.2f %9.2f %9d\n", width, "macro avg", macroP/n, macroR/n, macroF/n, m.Total)
+	}
+	if m.Total > 0 {
+		total := float64(m.Total)
+		fmt.Fprintf(&b, "%*s %9.2f %9.2f %9.2f %9d\n", width, "weighted avg", weightedP/total, weightedR/total, weightedF/total, m.Total)
+	}
+	return b.String()
+}
+
+// OrderLabels returns labels arranged so that those listed in order come
+// first, in that order, followed by the rest alphabetically. Entries of order
+// missing from labels are skipped, so one domain order can serve datasets
+// that only use some of its classes.
+func OrderLabels(labels, order []string) []string {
+	present := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		present[label] = true
+	}
+	ordered := make([]string, 0, len(labels))
+	for _, label := range order {
+		if present[label] {
+			ordered = append(ordered, label)
+			delete(present, label)
+		}
+	}
+	rest := make([]string, 0, len(present))
+	for label := range present {
+		rest = append(rest, label)
+	}
+	sort.Strings(rest)
+	return append(ordered, rest...)
+}
+
+// CohensKappa returns Cohen's kappa for the confusion matrix in m: observed
+// agreement between actual and predicted labels corrected for the agreement
+// expected by chance from their marginal distributions. When chance agreement
+// is already perfect (a single class throughout) kappa is undefined; it is
+// reported as 1 if every prediction is correct and 0 otherwise.
+func CohensKappa(m Metrics) float64 {
+	total := 0
+	actual := make(map[string]int)
+	predicted := make(map[string]int)
+	agree := 0
+	for a, row := range m.Confusion {
+		for p, count := range row {
+			total += count
+			actual[a] += count
+			predicted[p] += count
+			if a == p {
+				agree += count
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+
+	n := float64(total)
+	observed := float64(agree) / n
+	var expected float64
+	for label, count := range actual {
+		expected += (float64(count) / n) * (float64(predicted[label]) / n)
+	}
+	if expected >= 1 {
+		if observed >= 1 {
+			return 1
+		}
+		return 0
+	}
+	return (observed - expected) / (1 - expected)
+}
@@ -0,0 +1,97 @@
+package sentiment
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCohensKappa(t *testing.T) {
+	tests := []struct {
+		name      string
+		confusion map[string]map[string]int
+		want      float64
+	}{
+		// p_o = 35/50 = 0.7, p_e = 0.5*0.6 + 0.5*0.4 = 0.5, kappa = 0.2/0.5.
+		{"known matrix", map[string]map[string]int{
+			"yes": {"yes": 20, "no": 5},
+			"no":  {"yes": 10, "no": 15},
+		}, 0.4},
+		{"perfect agreement", map[string]map[string]int{
+			"yes": {"yes": 7},
+			"no":  {"no": 3},
+		}, 1},
+		// Always predicting "yes" agrees exactly as often as chance does.
+		{"chance agreement", map[string]map[string]int{
+			"yes": {"yes": 6},
+			"no":  {"yes": 4},
+		}, 0},
+		{"single class, all correct", map[string]map[string]int{"yes": {"yes": 5}}, 1},
+		{"empty", nil, 0},
+	}
+	for _, tt := range tests {
+		if got := CohensKappa(Metrics{Confusion: tt.confusion}); !approxEqual(got, tt.want) {
+			t.Errorf("%s: kappa = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClassificationReportIncludesKappa(t *testing.T) {
+	m := Metrics{
+		Total:   50,
+		Correct: 35,
+		Confusion: map[string]map[string]int{
+			"yes": {"yes": 20, "no": 5},
+			"no":  {"yes": 10, "no": 15},
+		},
+	}
+	report := ClassificationReport(m)
+	for _, line := range strings.Split(report, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == "kappa" {
+			if fields[1] != "0.40" {
+				t.Errorf("kappa line = %q, want 0.40", line)
+			}
+			return
+		}
+	}
+	t.Errorf("report has no kappa line:\n%s", report)
+}
+
+func TestOrderLabels(t *testing.T) {
+	labels := []string{"positive", "mixed", "negative", "neutral"}
+	tests := []struct {
+		order []string
+		want  []string
+	}{
+		{nil, []string{"mixed", "negative", "neutral", "positive"}},
+		{[]string{"negative", "neutral", "positive"}, []string{"negative", "neutral", "positive", "mixed"}},
+		{[]string{"positive", "unused"}, []string{"positive", "mixed", "negative", "neutral"}},
+	}
+	for _, tt := range tests {
+		if got := OrderLabels(labels, tt.order); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("OrderLabels(%v) = %v, want %v", tt.order, got, tt.want)
+		}
+	}
+}
+
+func TestClassificationReportOrdered(t *testing.T) {
+	m := Metrics{
+		Total:   4,
+		Correct: 3,
+		Confusion: map[string]map[string]int{
+			"positive": {"positive": 2},
+			"negative": {"negative": 1},
+			"neutral":  {"positive": 1},
+		},
+	}
+	report := ClassificationReportOrdered(m, []string{"positive", "neutral"})
+	var rows []string
+	for _, line := range strings.Split(report, "\n") {
+		if fields := strings.Fields(line); len(fields) == 5 {
+			rows = append(rows, fields[0])
+		}
+	}
+	if want := []string{"positive", "neutral", "negative"}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("report class rows = %v, want %v:\n%s", rows, want, report)
+	}
+}
@@ -0,0 +1,53 @@
+package sentiment
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// SampleDocument draws length tokens independently from class's learned
+// token distribution, P(token|class) ∝ stored count, and joins them with
+// spaces. It shows what the model "thinks" a document of the class looks
+// like. The same seed always yields the same document. The class name is
+// canonicalized like training labels. Unknown classes, classes without stored
+// words and a negative length are an error.
+func (nb *NaiveBayesClassifier) SampleDocument(class string, length int, seed int64) (string, error) {
+	if length < 0 {
+		return "", fmt.Errorf("length must be non-negative, got %d", length)
+	}
+	class = nb.canonicalLabel(class)
+	counts, ok := nb.classWordCounts[class]
+	if !ok {
+		return "", fmt.Errorf("unknown class %q", class)
+	}
+	tokens := make([]string, 0, len(counts))
+	for token, count := range counts {
+		if count > 0 {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) == 0 {
+		return "", fmt.Errorf("class %q has no words to sample", class)
+	}
+	sort.Strings(tokens)
+	cumulative := make([]float64, len(tokens))
+	var total float64
+	for i, token := range tokens {
+		total += counts[token]
+		cumulative[i] = total
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	words := make([]string, 0, length)
+	for i := 0; i < length; i++ {
+		target := rng.Float64() * total
+		idx := sort.SearchFloat64s(cumulative, target)
+		if idx == len(tokens) {
+			idx--
+		}
+		words = append(words, tokens[idx])
+	}
+	return strings.Join(words, " "), nil
+}
@@ -0,0 +1,36 @@
+package sentiment
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSampleDocument(t *testing.T) {
+	nb := trainTiny()
+	first, err := nb.SampleDocument("positive", 8, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := nb.SampleDocument(" Positive ", 8, 7); again != first {
+		t.Errorf("same seed gave %q and %q", first, again)
+	}
+	words := strings.Fields(first)
+	if len(words) != 8 {
+		t.Fatalf("sample %q has %d words, want 8", first, len(words))
+	}
+	for _, word := range words {
+		if word != "good" && word != "great" {
+			t.Errorf("sample %q contains %q, which positive never saw", first, word)
+		}
+	}
+
+	if got, err := nb.SampleDocument("positive", 0, 7); err != nil || got != "" {
+		t.Errorf("length 0 = %q, %v; want an empty document", got, err)
+	}
+	if _, err := nb.SampleDocument("neutral", 5, 7); err == nil {
+		t.Error("unknown class: want an error")
+	}
+	if _, err := nb.SampleDocument("positive", -1, 7); err == nil {
+		t.Error("negative length: want an error")
+	}
+}
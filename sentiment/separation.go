@@ -0,0 +1,73 @@
+package sentiment
+
+import (
+	"math"
+	"sort"
+)
+
+// ClassSeparation summarizes how distinguishable the trained classes are as
+// the mean Kullback-Leibler divergence, in bits, between the token
+// distributions of every ordered pair of classes. Each class distribution is
+// its smoothed P(token|class), computed as Predict does, over the shared
+// vocabulary and renormalized to sum to 1. Every smoothing strategy,
+// including SmoothingNone's floor, keeps the probabilities positive so the
+// divergence stays finite, but lighter smoothing yields larger values, so
+// only compare models smoothed alike. Higher values mean more separable
+// classes. It returns 0 with fewer than two trained classes or an empty
+// vocabulary.
+func (nb *NaiveBayesClassifier) ClassSeparation() float64 {
+	var classes []string
+	for class, docCount := range nb.classDocCounts {
+		if docCount > 0 {
+			classes = append(classes, class)
+		}
+	}
+	if len(classes) < 2 || len(nb.vocabulary) == 0 {
+		return 0
+	}
+	sort.Strings(classes)
+	vocab := make([]string, 0, len(nb.vocabulary))
+	for token := range nb.vocabulary {
+		vocab = append(vocab, token)
+	}
+	sort.Strings(vocab)
+
+	dists := make([][]float64, len(classes))
+	for i, class := range classes {
+		dist := make([]float64, len(vocab))
+		var sum float64
+		for j, token := range vocab {
+			dist[j] = nb.wordProbability(class, token)
+			sum += dist[j]
+		}
+		for j := range dist {
+			dist[j] /= sum
+		}
+		dists[i] = dist
+	}
+
+	var total float64
+	pairs := 0
+	for i := range dists {
+		for j := range dists {
+			if i == j {
+				continue
+			}
+			total += klDivergence(dists[i], dists[j])
+			pairs++
+		}
+	}
+	return total / float64(pairs)
+}
+
+// klDivergence returns KL(p || q) in bits. Terms where p is zero contribute
+// nothing; a zero in q where p is positive makes the result +Inf.
+func klDivergence(p, q []float64) float64 {
+	var kl float64
+	for i := range p {
+		if p[i] > 0 {
+			kl += p[i] * math.Log2(p[i]/q[i])
+		}
+	}
+	return kl
+}
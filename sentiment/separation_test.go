@@ -0,0 +1,68 @@
+package sentiment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClassSeparationKnownModel(t *testing.T) {
+	// trainTiny over (good, great, bad): positive (3/6, 2/6, 1/6), negative
+	// (2/5, 1/5, 2/5).
+	p := []float64{3.0 / 6, 2.0 / 6, 1.0 / 6}
+	q := []float64{2.0 / 5, 1.0 / 5, 2.0 / 5}
+	var pq, qp float64
+	for i := range p {
+		pq += p[i] * math.Log2(p[i]/q[i])
+		qp += q[i] * math.Log2(q[i]/p[i])
+	}
+	if got, want := trainTiny().ClassSeparation(), (pq+qp)/2; !approxEqual(got, want) {
+		t.Errorf("ClassSeparation = %v, want %v", got, want)
+	}
+}
+
+func TestClassSeparationDefaultDataset(t *testing.T) {
+	nb := NewNaiveBayesClassifier()
+	nb.TrainBatch(DefaultDataset())
+	got := nb.ClassSeparation()
+	// About 0.30 bits: the classes share few words, but Laplace smoothing
+	// over a small corpus keeps their distributions close.
+	if math.IsNaN(got) || got < 0.1 || got > 2 {
+		t.Errorf("ClassSeparation = %v, want a value in [0.1, 2] bits", got)
+	}
+	nb.SetSmoothingStrategy(SmoothingJeffreys)
+	if lighter := nb.ClassSeparation(); lighter <= got {
+		t.Errorf("Jeffreys smoothing separation = %v, want more than Laplace's %v", lighter, got)
+	}
+
+	// The same texts under both labels are indistinguishable.
+	same := NewNaiveBayesClassifier()
+	for _, doc := range DefaultDataset() {
+		same.Train(doc.Text, "positive")
+		same.Train(doc.Text, "negative")
+	}
+	if s := same.ClassSeparation(); !approxEqual(s, 0) {
+		t.Errorf("identical classes have separation %v, want 0", s)
+	}
+	unsmoothed := trainTiny()
+	unsmoothed.SetSmoothingStrategy(SmoothingNone)
+	if s := unsmoothed.ClassSeparation(); math.IsInf(s, 0) || math.IsNaN(s) {
+		t.Errorf("unsmoothed separation = %v, want a finite value", s)
+	}
+
+	single := NewNaiveBayesClassifier()
+	single.Train("good", "positive")
+	if s := single.ClassSeparation(); s != 0 {
+		t.Errorf("single class separation = %v, want 0", s)
+	}
+}
+
+func TestClassSeparationDeterministic(t *testing.T) {
+	nb := NewNaiveBayesClassifier()
+	nb.TrainBatch(DefaultDataset())
+	want := nb.ClassSeparation()
+	for i := 0; i < 50; i++ {
+		if got := nb.ClassSeparation(); got != want {
+			t.Fatalf("ClassSeparation = %v on call %d, want exactly %v", got, i+2, want)
+		}
+	}
+}
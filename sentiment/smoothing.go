@@ -0,0 +1,67 @@
+package sentiment
+
+import "fmt"
+
+// SmoothingStrategy names how unseen and rare token counts are smoothed when
+// estimating P(token|class).
+type SmoothingStrategy string
+
+const (
+	// SmoothingLaplace adds one to every count (alpha = 1).
+	SmoothingLaplace SmoothingStrategy = "laplace"
+	// SmoothingLidstone adds a caller-supplied alpha to every count.
+	SmoothingLidstone SmoothingStrategy = "lidstone"
+	// SmoothingJeffreys adds one half to every count (alpha = 0.5).
+	SmoothingJeffreys SmoothingStrategy = "jeffreys"
+	// SmoothingNone uses raw relative frequencies. Tokens never seen with a
+	// class fall back to a tiny fixed probability so scores stay finite.
+	SmoothingNone SmoothingStrategy = "none"
+)
+
+// unsmoothedFloor is the probability assigned to zero-count tokens when
+// smoothing is disabled, avoiding log(0).
+const unsmoothedFloor = 1e-10
+
+// ParseSmoothingStrategy converts a strategy name into a SmoothingStrategy.
+func ParseSmoothingStrategy(name string) (SmoothingStrategy, error) {
+	switch strategy := SmoothingStrategy(name); strategy {
+	case SmoothingLaplace, SmoothingLidstone, SmoothingJeffreys, SmoothingNone:
+		return strategy, nil
+	}
+	return "", fmt.Errorf("unknown smoothing strategy %q (expected laplace|lidstone|jeffreys|none)", name)
+}
+
+// SetSmoothingStrategy selects the smoothing applied by Predict. The Lidstone
+// strategy uses the alpha configured with SetLidstoneAlpha.
+func (nb *NaiveBayesClassifier) SetSmoothingStrategy(strategy SmoothingStrategy) {
+	nb.generation++
+	nb.smoothing = strategy
+}
+
+// SetLidstoneAlpha sets the additive constant used by SmoothingLidstone.
+func (nb *NaiveBayesClassifier) SetLidstoneAlpha(alpha float64) {
+	nb.generation++
+	nb.lidstoneAlpha = alpha
+}
+
+// SmoothingStrategy returns the currently configured strategy.
+func (nb *NaiveBayesClassifier) SmoothingStrategy() SmoothingStrategy {
+	if nb.smoothing == "" {
+		return SmoothingLaplace
+	}
+	return nb.smoothing
+}
+
+// smoothingAlpha returns the additive constant implied by the strategy.
+func (nb *NaiveBayesClassifier) smoothingAlpha() float64 {
+	switch nb.SmoothingStrategy() {
+	case SmoothingLidstone:
+		return nb.lidstoneAlpha
+	case SmoothingJeffreys:
+		return 0.5
+	case SmoothingNone:
+		return 0
+	default:
+		return 1
+	}
+}
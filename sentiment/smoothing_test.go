@@ -0,0 +1,82 @@
+package sentiment
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSmoothingStrategies(t *testing.T) {
+	// trainTiny: positive has good x2 and great (3 words), |V| = 3.
+	tests := []struct {
+		strategy  SmoothingStrategy
+		alpha     float64
+		wantGood  float64
+		wantOOV   float64
+		wantAlpha float64
+	}{
+		{SmoothingLaplace, 0, 3.0 / 6, 1.0 / 6, 1},
+		{SmoothingLidstone, 0.2, 2.2 / 3.6, 0.2 / 3.6, 0.2},
+		{SmoothingJeffreys, 0, 2.5 / 4.5, 0.5 / 4.5, 0.5},
+		{SmoothingNone, 0, 2.0 / 3, unsmoothedFloor, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			nb := trainTiny()
+			nb.SetSmoothingStrategy(tt.strategy)
+			nb.SetLidstoneAlpha(tt.alpha)
+			if got := nb.smoothingAlpha(); got != tt.wantAlpha {
+				t.Errorf("alpha = %v, want %v", got, tt.wantAlpha)
+			}
+			if got := nb.WordClassProbabilities("good")["positive"]; !approxEqual(got, tt.wantGood) {
+				t.Errorf("P(good|positive) = %v, want %v", got, tt.wantGood)
+			}
+			if got := nb.WordClassProbabilities("bad")["positive"]; !approxEqual(got, tt.wantOOV) {
+				t.Errorf("P(bad|positive) = %v, want %v", got, tt.wantOOV)
+			}
+			label, probs := nb.Predict("bad bad bad")
+			if label != "negative" {
+				t.Errorf("Predict(bad bad bad) = %s %v, want negative", label, probs)
+			}
+		})
+	}
+}
+
+func TestSmoothingStrategySnapshotRoundTrip(t *testing.T) {
+	for _, strategy := range []SmoothingStrategy{SmoothingLaplace, SmoothingLidstone, SmoothingJeffreys, SmoothingNone} {
+		nb := trainTiny()
+		nb.SetSmoothingStrategy(strategy)
+		nb.SetLidstoneAlpha(0.3)
+
+		payload, err := json.Marshal(nb.Snapshot())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var snapshot Snapshot
+		if err := json.Unmarshal(payload, &snapshot); err != nil {
+			t.Fatal(err)
+		}
+		loaded := NewNaiveBayesClassifier()
+		loaded.LoadSnapshot(snapshot)
+
+		if got := loaded.SmoothingStrategy(); got != strategy {
+			t.Errorf("restored strategy = %s, want %s", got, strategy)
+		}
+		want := nb.WordClassProbabilities("great")["negative"]
+		if got := loaded.WordClassProbabilities("great")["negative"]; !approxEqual(got, want) {
+			t.Errorf("%s: restored P(great|negative) = %v, want %v", strategy, got, want)
+		}
+	}
+}
+
+func TestParseSmoothingStrategy(t *testing.T) {
+	for _, name := range []string{"laplace", "lidstone", "jeffreys", "none"} {
+		if got, err := ParseSmoothingStrategy(name); err != nil || string(got) != name {
+			t.Errorf("ParseSmoothingStrategy(%q) = %q, %v", name, got, err)
+		}
+	}
+	for _, name := range []string{"", "Laplace", "kneser-ney"} {
+		if _, err := ParseSmoothingStrategy(name); err == nil {
+			t.Errorf("ParseSmoothingStrategy(%q) succeeded, want an error", name)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package sentiment
+
+import (
+	"fmt"
+	"math"
+)
+
+// TermFrequencyMode controls how often a token is counted when it occurs
+// several times in one training document.
+//
+// The mode only affects training. Predict still adds one log-likelihood term
+// per occurrence (up to MaxTokenRepeats), so a word repeated in the input
+// weighs more in every mode; with TFBinary or TFLog the stored likelihoods
+// simply grow more slowly for words that tend to repeat within documents.
+type TermFrequencyMode string
+
+const (
+	// TFCount adds the raw number of occurrences (the default).
+	TFCount TermFrequencyMode = "count"
+	// TFBinary adds 1 for every token present, however often it occurs,
+	// which approximates a Bernoulli model inside the multinomial one.
+	TFBinary TermFrequencyMode = "binary"
+	// TFLog adds log(1+count), the same as SublinearTF.
+	TFLog TermFrequencyMode = "log"
+)
+
+// ParseTermFrequencyMode converts a mode name into a TermFrequencyMode.
+func ParseTermFrequencyMode(name string) (TermFrequencyMode, error) {
+	switch mode := TermFrequencyMode(name); mode {
+	case TFCount, TFBinary, TFLog:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown term frequency mode %q (expected count|binary|log)", name)
+}
+
+// TermFrequency selects how repeated tokens within a training document are
+// counted. Stored word counts become fractional with TFLog.
+func TermFrequency(mode TermFrequencyMode) Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.tfMode = mode
+	}
+}
+
+// termFrequency converts the number of times a token occurs in one document
+// into the amount added to its class count.
+func (nb *NaiveBayesClassifier) termFrequency(count int) float64 {
+	switch nb.tfMode {
+	case TFBinary:
+		return 1
+	case TFLog:
+		return math.Log1p(float64(count))
+	}
+	return float64(count)
+}
+
+// MaxTokenRepeats limits how many occurrences of any one feature Predict
+// scores, so keyword stuffing such as "good good good ..." cannot push a
+// prediction arbitrarily far towards one class. Training is unaffected. Values
+// below 1 leave Predict uncapped.
+func MaxTokenRepeats(k int) Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.maxTokenRepeats = k
+	}
+}
+
+// capRepeats returns tokens without the occurrences of each token beyond the
+// first k, preserving order, along with the matching entries of weights when
+// weights is not nil.
+func capRepeats(tokens []string, weights []float64, k int) ([]string, []float64) {
+	seen := make(map[string]int, len(tokens))
+	capped := make([]string, 0, len(tokens))
+	var cappedWeights []float64
+	if weights != nil {
+		cappedWeights = make([]float64, 0, len(tokens))
+	}
+	for i, token := range tokens {
+		if seen[token] >= k {
+			continue
+		}
+		seen[token]++
+		capped = append(capped, token)
+		if weights != nil {
+			cappedWeights = append(cappedWeights, weights[i])
+		}
+	}
+	return capped, cappedWeights
+}
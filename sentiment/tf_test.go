@@ -0,0 +1,62 @@
+package sentiment
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestTermFrequencyModes(t *testing.T) {
+	tests := []struct {
+		mode      TermFrequencyMode
+		wantGreat float64
+		wantPhone float64
+		wantTotal float64
+	}{
+		{TFCount, 3, 1, 4},
+		{TFBinary, 1, 1, 2},
+		{TFLog, math.Log(4), math.Log(2), math.Log(4) + math.Log(2)},
+	}
+	for _, tt := range tests {
+		nb := NewNaiveBayesClassifier(TermFrequency(tt.mode))
+		nb.Train("great great phone great", "positive")
+		counts := nb.classWordCounts["positive"]
+		if !approxEqual(counts["great"], tt.wantGreat) || !approxEqual(counts["phone"], tt.wantPhone) {
+			t.Errorf("%s: counts = %v, want great %v and phone %v", tt.mode, counts, tt.wantGreat, tt.wantPhone)
+		}
+		if got := nb.classTotalWords["positive"]; !approxEqual(got, tt.wantTotal) {
+			t.Errorf("%s: total words = %v, want %v", tt.mode, got, tt.wantTotal)
+		}
+	}
+}
+
+func TestParseTermFrequencyMode(t *testing.T) {
+	for _, name := range []string{"count", "binary", "log"} {
+		if got, err := ParseTermFrequencyMode(name); err != nil || string(got) != name {
+			t.Errorf("ParseTermFrequencyMode(%q) = %q, %v", name, got, err)
+		}
+	}
+	if _, err := ParseTermFrequencyMode("tfidf"); err == nil {
+		t.Error("ParseTermFrequencyMode(tfidf) succeeded, want an error")
+	}
+}
+
+func TestMaxTokenRepeats(t *testing.T) {
+	nb := trainTiny(MaxTokenRepeats(2))
+	if nb.classWordCounts["positive"]["good"] != 2 {
+		t.Errorf("training count of good = %v, want 2: the cap must not affect training", nb.classWordCounts["positive"]["good"])
+	}
+
+	stuffed := strings.TrimSpace(strings.Repeat("great ", 20))
+	// Only two "great" terms count: (1/2)(2/6)^2 against (1/2)(1/5)^2.
+	label, probs := nb.Predict(stuffed)
+	if label != "positive" || !approxEqual(probs["positive"], 25.0/34) {
+		t.Errorf("capped Predict = %s %v, want positive at 25/34", label, probs)
+	}
+	if _, twice := nb.Predict("great great"); !approxEqual(probs["positive"], twice["positive"]) {
+		t.Errorf("20 repeats scored %v, want the 2-repeat score %v", probs["positive"], twice["positive"])
+	}
+	if _, uncapped := trainTiny().Predict(stuffed); uncapped["positive"] < 0.9999 {
+		t.Errorf("uncapped P(positive) = %v, want the stuffed text to be near certain", uncapped["positive"])
+	}
+}
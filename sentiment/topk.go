@@ -0,0 +1,61 @@
+package sentiment
+
+import "sort"
+
+// ClassProbability pairs a class label with its posterior probability.
+type ClassProbability struct {
+	Label       string
+	Probability float64
+}
+
+// PredictTopK returns the k most probable classes for text, most probable
+// first. Ties are broken alphabetically so the order is stable. Fewer than k
+// entries are returned when the model knows fewer classes, and none when k is
+// not positive.
+func (nb *NaiveBayesClassifier) PredictTopK(text string, k int) []ClassProbability {
+	if k <= 0 {
+		return nil
+	}
+	_, probs := nb.Predict(text)
+	ranked := RankProbabilities(probs)
+	if len(ranked) > k {
+		ranked = ranked[:k]
+	}
+	return ranked
+}
+
+// RankProbabilities orders a class distribution from most to least probable,
+// breaking ties alphabetically.
+func RankProbabilities(probs map[string]float64) []ClassProbability {
+	ranked := make([]ClassProbability, 0, len(probs))
+	for label, p := range probs {
+		ranked = append(ranked, ClassProbability{Label: label, Probability: p})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Probability != ranked[j].Probability {
+			return ranked[i].Probability > ranked[j].Probability
+		}
+		return ranked[i].Label < ranked[j].Label
+	})
+	return ranked
+}
+
+// TopKAccuracy returns the fraction of docs whose label is among the k most
+// probable classes. With k = 1 it matches Evaluate's accuracy except for ties.
+// It returns 0 for an empty slice or a non-positive k.
+func TopKAccuracy(nb *NaiveBayesClassifier, docs []Document, k int) float64 {
+	if len(docs) == 0 || k <= 0 {
+		return 0
+	}
+	hits := 0
+	for _, doc := range docs {
+		actual := nb.canonicalLabel(doc.Label)
+		for _, candidate := range nb.PredictTopK(doc.Text, k) {
+			if candidate.Label == actual {
+				hits++
+				break
+			}
+		}
+	}
+	return float64(hits) / float64(len(docs))
+}
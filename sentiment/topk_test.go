@@ -0,0 +1,52 @@
+package sentiment
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTopKAccuracy(t *testing.T) {
+	nb := NewNaiveBayesClassifier()
+	nb.Train("apple apple", "a")
+	nb.Train("apple banana", "b")
+	nb.Train("cherry", "c")
+	docs := []Document{
+		{Text: "apple", Label: "b"}, // a ranks first, b second
+		{Text: "cherry", Label: "c"},
+	}
+
+	top := nb.PredictTopK("apple", 3)
+	labels := make([]string, len(top))
+	for i, p := range top {
+		labels[i] = p.Label
+	}
+	if !reflect.DeepEqual(labels, []string{"a", "b", "c"}) {
+		t.Fatalf("PredictTopK(apple) = %v, want a, b, c", top)
+	}
+
+	tests := []struct {
+		k    int
+		want float64
+	}{
+		{0, 0},
+		{1, 0.5},
+		{2, 1},
+		{5, 1},
+	}
+	for _, tt := range tests {
+		if got := TopKAccuracy(nb, docs, tt.k); got != tt.want {
+			t.Errorf("TopKAccuracy(k=%d) = %v, want %v", tt.k, got, tt.want)
+		}
+	}
+	if got := Evaluate(nb, docs).Accuracy(); got != TopKAccuracy(nb, docs, 1) {
+		t.Errorf("top-1 accuracy %v differs from Evaluate's %v", TopKAccuracy(nb, docs, 1), got)
+	}
+}
+
+func TestRankProbabilitiesBreaksTiesAlphabetically(t *testing.T) {
+	got := RankProbabilities(map[string]float64{"z": 0.25, "m": 0.5, "a": 0.25})
+	want := []ClassProbability{{"m", 0.5}, {"a", 0.25}, {"z", 0.25}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("RankProbabilities = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,28 @@
+package sentiment
+
+import (
+	"context"
+	"time"
+)
+
+// TrainStats describes a completed training run.
+type TrainStats struct {
+	Documents int
+	Duration  time.Duration
+}
+
+// DocsPerSecond returns the training throughput, or 0 when no time elapsed.
+func (s TrainStats) DocsPerSecond() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.Documents) / s.Duration.Seconds()
+}
+
+// TrainBatchTimed behaves like TrainBatch and reports how many documents were
+// trained and how long it took, for tracking training performance.
+func (nb *NaiveBayesClassifier) TrainBatchTimed(docs []Document) TrainStats {
+	start := time.Now()
+	n, _ := nb.TrainBatchContext(context.Background(), docs)
+	return TrainStats{Documents: n, Duration: time.Since(start)}
+}
@@ -0,0 +1,30 @@
+package sentiment
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrainBatchTimed(t *testing.T) {
+	docs := DefaultDataset()
+	nb := NewNaiveBayesClassifier()
+	stats := nb.TrainBatchTimed(docs)
+	if stats.Documents != len(docs) || nb.totalDocs != float64(len(docs)) {
+		t.Errorf("stats report %d documents and the model holds %v, want %d", stats.Documents, nb.totalDocs, len(docs))
+	}
+	if stats.Duration <= 0 {
+		t.Errorf("Duration = %v, want a positive training time", stats.Duration)
+	}
+	if want := float64(len(docs)) / stats.Duration.Seconds(); !approxEqual(stats.DocsPerSecond(), want) {
+		t.Errorf("DocsPerSecond = %v, want %v", stats.DocsPerSecond(), want)
+	}
+}
+
+func TestTrainStatsDocsPerSecond(t *testing.T) {
+	if got := (TrainStats{Documents: 50, Duration: 2 * time.Second}).DocsPerSecond(); got != 25 {
+		t.Errorf("DocsPerSecond = %v, want 25", got)
+	}
+	if got := (TrainStats{Documents: 50}).DocsPerSecond(); got != 0 {
+		t.Errorf("DocsPerSecond without elapsed time = %v, want 0", got)
+	}
+}
@@ -0,0 +1,208 @@
+package sentiment
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// MaxVocabSize bounds the vocabulary to n tokens during training. Whenever a
+// Train call pushes the vocabulary past n, the least frequent tokens (summed
+// across classes) are evicted together with their counts until the vocabulary
+// is back at 90% of n, leaving headroom so eviction does not run on every new
+// token. A value of 0 disables the cap.
+func MaxVocabSize(n int) Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.maxVocabSize = n
+	}
+}
+
+// enforceVocabCap evicts the rarest tokens when the vocabulary exceeds the
+// configured maximum.
+func (nb *NaiveBayesClassifier) enforceVocabCap() {
+	if nb.maxVocabSize <= 0 || len(nb.vocabulary) <= nb.maxVocabSize {
+		return
+	}
+	target := nb.maxVocabSize - nb.maxVocabSize/10
+	if target <= 0 {
+		target = nb.maxVocabSize
+	}
+
+	type tokenTotal struct {
+		token string
+		count float64
+	}
+	totals := make([]tokenTotal, 0, len(nb.vocabulary))
+	for token := range nb.vocabulary {
+		var sum float64
+		for _, counts := range nb.classWordCounts {
+			sum += counts[token]
+		}
+		totals = append(totals, tokenTotal{token: token, count: sum})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].count != totals[j].count {
+			return totals[i].count < totals[j].count
+		}
+		return totals[i].token < totals[j].token
+	})
+
+	for _, entry := range totals[:len(totals)-target] {
+		delete(nb.vocabulary, entry.token)
+		for class, counts := range nb.classWordCounts {
+			if count, ok := counts[entry.token]; ok {
+				nb.classTotalWords[class] -= count
+				nb.updateSingletons(class, count, 0)
+				delete(counts, entry.token)
+			}
+		}
+	}
+}
+
+// FreezeVocabulary fixes the feature space: subsequent Train calls only update
+// counts for tokens already in the vocabulary and ignore unknown ones, so
+// online training cannot grow the vocabulary or shift smoothing denominators.
+// Reset unfreezes the vocabulary.
+func (nb *NaiveBayesClassifier) FreezeVocabulary() {
+	nb.vocabFrozen = true
+}
+
+// SetMaskedTokens makes Predict ignore the given tokens, replacing any
+// previously masked set; calling it without arguments unmasks everything.
+// It is a quick mitigation for spurious features, such as a leaked label
+// word, discovered after training: the learned counts are untouched and the
+// mask is not saved in snapshots, so it must be set again after loading.
+// Tokens are lowercased like WordClassProbabilities' argument. Only exact
+// features are masked, so n-grams containing a masked word still count.
+func (nb *NaiveBayesClassifier) SetMaskedTokens(tokens ...string) {
+	nb.generation++
+	nb.maskedTokens = nil
+	if len(tokens) == 0 {
+		return
+	}
+	nb.maskedTokens = make(map[string]struct{}, len(tokens))
+	for _, token := range tokens {
+		token = strings.ToLower(strings.TrimSpace(token))
+		if nb.hashBuckets > 0 {
+			token = hashFeature(token, nb.hashBuckets)
+		}
+		nb.maskedTokens[token] = struct{}{}
+	}
+}
+
+// SetClassStopWords makes Predict ignore words only while scoring the class
+// they are listed under, for words that carry signal for some classes but are
+// noise for others. It replaces any previously set lists; a nil or empty map
+// disables the feature. Because every likelihood term lowers a score,
+// skipping a word raises the listed class relative to the others. Class
+// names are canonicalized like training labels and words are lowercased like
+// SetMaskedTokens' arguments. Like the mask, the lists are not saved in
+// snapshots.
+func (nb *NaiveBayesClassifier) SetClassStopWords(stopWords map[string][]string) {
+	nb.generation++
+	nb.classStopWords = nil
+	if len(stopWords) == 0 {
+		return
+	}
+	nb.classStopWords = make(map[string]map[string]struct{}, len(stopWords))
+	for class, words := range stopWords {
+		class = nb.canonicalLabel(class)
+		set := nb.classStopWords[class]
+		if set == nil {
+			set = make(map[string]struct{}, len(words))
+			nb.classStopWords[class] = set
+		}
+		for _, word := range words {
+			word = strings.ToLower(strings.TrimSpace(word))
+			if nb.hashBuckets > 0 {
+				word = hashFeature(word, nb.hashBuckets)
+			}
+			set[word] = struct{}{}
+		}
+	}
+}
+
+// VocabularyEntry is a vocabulary token with its count summed across classes.
+type VocabularyEntry struct {
+	Token string
+	Count float64
+}
+
+// Vocabulary returns every token in the vocabulary, sorted alphabetically,
+// with its total count across all classes.
+func (nb *NaiveBayesClassifier) Vocabulary() []VocabularyEntry {
+	entries := make([]VocabularyEntry, 0, len(nb.vocabulary))
+	for token := range nb.vocabulary {
+		var total float64
+		for _, counts := range nb.classWordCounts {
+			total += counts[token]
+		}
+		entries = append(entries, VocabularyEntry{Token: token, Count: total})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Token < entries[j].Token
+	})
+	return entries
+}
+
+// FeatureLogProbabilities returns the learned parameters as a dense matrix:
+// matrix[i][j] is the smoothed log P(vocab[j] | classes[i]), computed exactly
+// as Predict does. Classes and vocabulary are sorted alphabetically; classes
+// with no documents are omitted.
+func (nb *NaiveBayesClassifier) FeatureLogProbabilities() (classes, vocab []string, matrix [][]float64) {
+	for class, docCount := range nb.classDocCounts {
+		if docCount > 0 {
+			classes = append(classes, class)
+		}
+	}
+	sort.Strings(classes)
+	vocab = make([]string, 0, len(nb.vocabulary))
+	for token := range nb.vocabulary {
+		vocab = append(vocab, token)
+	}
+	sort.Strings(vocab)
+
+	matrix = make([][]float64, len(classes))
+	for i, class := range classes {
+		row := make([]float64, len(vocab))
+		for j, token := range vocab {
+			row[j] = math.Log(nb.wordProbability(class, token))
+		}
+		matrix[i] = row
+	}
+	return classes, vocab, matrix
+}
+
+// MinDocumentFrequency makes TrainBatch drop every feature that occurs in
+// fewer than n of the batch's documents, so rare tokens never enter the
+// vocabulary. Unlike MaxVocabSize it decides before counting rather than
+// evicting afterwards. It needs the whole batch, so Train and TrainWeighted
+// ignore it. Values of 1 or less disable the threshold.
+func MinDocumentFrequency(n int) Option {
+	return func(nb *NaiveBayesClassifier) {
+		nb.minDocFreq = n
+	}
+}
+
+// frequentFeatures returns the set of features that appear in at least n of
+// the documents.
+func frequentFeatures(docs [][]string, n int) map[string]bool {
+	df := make(map[string]int)
+	for _, features := range docs {
+		seen := make(map[string]struct{}, len(features))
+		for _, token := range features {
+			if _, ok := seen[token]; ok {
+				continue
+			}
+			seen[token] = struct{}{}
+			df[token]++
+		}
+	}
+	frequent := make(map[string]bool, len(df))
+	for token, count := range df {
+		if count >= n {
+			frequent[token] = true
+		}
+	}
+	return frequent
+}
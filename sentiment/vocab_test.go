@@ -0,0 +1,200 @@
+package sentiment
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestMaxVocabSize(t *testing.T) {
+	nb := NewNaiveBayesClassifier(MaxVocabSize(10))
+	for i := 0; i < 30; i++ {
+		label := "positive"
+		if i%2 == 1 {
+			label = "negative"
+		}
+		nb.Train(fmt.Sprintf("common frequent rare%d", i), label)
+		if len(nb.vocabulary) > 10 {
+			t.Fatalf("after document %d the vocabulary has %d tokens, want at most 10", i, len(nb.vocabulary))
+		}
+	}
+	for _, token := range []string{"common", "frequent"} {
+		if _, ok := nb.vocabulary[token]; !ok {
+			t.Errorf("high-frequency token %q was evicted", token)
+		}
+	}
+	if got := nb.classWordCounts["positive"]["common"] + nb.classWordCounts["negative"]["common"]; got != 30 {
+		t.Errorf("count of common = %v, want 30", got)
+	}
+	for class, counts := range nb.classWordCounts {
+		var sum float64
+		for token, count := range counts {
+			if _, ok := nb.vocabulary[token]; !ok {
+				t.Errorf("class %s still counts evicted token %q", class, token)
+			}
+			sum += count
+		}
+		if sum != nb.classTotalWords[class] {
+			t.Errorf("class %s total words = %v, but its counts sum to %v", class, nb.classTotalWords[class], sum)
+		}
+	}
+}
+
+func TestMaxVocabSizeDisabled(t *testing.T) {
+	nb := NewNaiveBayesClassifier()
+	for i := 0; i < 30; i++ {
+		nb.Train(fmt.Sprintf("rare%d", i), "positive")
+	}
+	if len(nb.vocabulary) != 30 {
+		t.Errorf("uncapped vocabulary has %d tokens, want 30", len(nb.vocabulary))
+	}
+}
+
+func TestVocabulary(t *testing.T) {
+	nb := trainTiny()
+	nb.Train("great bad bad", "negative")
+	want := []VocabularyEntry{{"bad", 3}, {"good", 3}, {"great", 2}}
+	if got := nb.Vocabulary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Vocabulary = %v, want %v", got, want)
+	}
+}
+
+func TestFreezeVocabulary(t *testing.T) {
+	nb := trainTiny()
+	nb.FreezeVocabulary()
+	nb.Train("good terrible", "negative")
+
+	if _, known := nb.vocabulary["terrible"]; known || len(nb.vocabulary) != 3 {
+		t.Errorf("frozen vocabulary = %v, want the original 3 tokens", nb.vocabulary)
+	}
+	if got := nb.classWordCounts["negative"]["good"]; got != 2 {
+		t.Errorf("negative good count = %v, want 2: known tokens still train", got)
+	}
+	if got := nb.classTotalWords["negative"]; got != 3 {
+		t.Errorf("negative total words = %v, want 3 without the dropped token", got)
+	}
+	if nb.classDocCounts["negative"] != 2 {
+		t.Errorf("negative doc count = %v, want 2", nb.classDocCounts["negative"])
+	}
+
+	nb.Reset()
+	nb.Train("terrible", "negative")
+	if _, known := nb.vocabulary["terrible"]; !known {
+		t.Error("Reset did not unfreeze the vocabulary")
+	}
+}
+
+func TestFeatureLogProbabilities(t *testing.T) {
+	nb := trainTiny()
+	classes, vocab, matrix := nb.FeatureLogProbabilities()
+	if !reflect.DeepEqual(classes, []string{"negative", "positive"}) {
+		t.Errorf("classes = %v, want [negative positive]", classes)
+	}
+	if !reflect.DeepEqual(vocab, []string{"bad", "good", "great"}) {
+		t.Errorf("vocab = %v, want [bad good great]", vocab)
+	}
+	if len(matrix) != len(classes) {
+		t.Fatalf("matrix has %d rows, want %d", len(matrix), len(classes))
+	}
+	for i, row := range matrix {
+		if len(row) != len(vocab) {
+			t.Fatalf("row %d has %d columns, want %d", i, len(row), len(vocab))
+		}
+	}
+	// log P(great|positive) = log((1 + 1) / (3 + 3)).
+	if got := matrix[1][2]; !approxEqual(got, math.Log(2.0/6)) {
+		t.Errorf("matrix[positive][great] = %v, want log(2/6)", got)
+	}
+	if got, want := matrix[0][0], math.Log(nb.WordClassProbabilities("bad")["negative"]); !approxEqual(got, want) {
+		t.Errorf("matrix[negative][bad] = %v, want %v", got, want)
+	}
+}
+
+func TestMinDocumentFrequency(t *testing.T) {
+	docs := []Document{
+		{Text: "great great rare1", Label: "positive"},
+		{Text: "great fine", Label: "positive"},
+		{Text: "awful fine rare2", Label: "negative"},
+	}
+	nb := NewNaiveBayesClassifier(MinDocumentFrequency(2))
+	nb.TrainBatch(docs)
+
+	// A token repeated within one document still has a document frequency of 1.
+	want := []VocabularyEntry{{"fine", 2}, {"great", 3}}
+	if got := nb.Vocabulary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Vocabulary = %v, want %v", got, want)
+	}
+	if nb.totalDocs != 3 || nb.classDocCounts["negative"] != 1 {
+		t.Errorf("totalDocs = %v, negative docs = %v; documents must still be counted", nb.totalDocs, nb.classDocCounts["negative"])
+	}
+
+	// Train is outside any batch, so it ignores the threshold.
+	nb.Train("unique", "negative")
+	if _, known := nb.vocabulary["unique"]; !known {
+		t.Error("Train applied the document frequency threshold")
+	}
+
+	plain := NewNaiveBayesClassifier(MinDocumentFrequency(1))
+	plain.TrainBatch(docs)
+	if len(plain.vocabulary) != 5 {
+		t.Errorf("MinDocumentFrequency(1) kept %d tokens, want all 5", len(plain.vocabulary))
+	}
+}
+
+func TestSetMaskedTokens(t *testing.T) {
+	nb := trainTiny()
+	if got, _ := nb.Predict("good bad bad"); got != "negative" {
+		t.Fatalf("unmasked Predict(good bad bad) = %s, want negative", got)
+	}
+	_, want := nb.Predict("good")
+
+	nb.SetMaskedTokens(" BAD ")
+	label, probs := nb.Predict("good bad bad")
+	if label != "positive" || !approxEqual(probs["positive"], want["positive"]) {
+		t.Errorf("masked Predict(good bad bad) = %s %v, want Predict(good) = positive %v", label, probs, want)
+	}
+	if got := nb.classWordCounts["negative"]["bad"]; got != 1 {
+		t.Errorf("masking changed the learned count of bad to %v", got)
+	}
+
+	restored := NewNaiveBayesClassifier()
+	restored.LoadSnapshot(nb.Snapshot())
+	if got, _ := restored.Predict("good bad bad"); got != "negative" {
+		t.Errorf("the mask survived a snapshot round-trip: Predict = %s", got)
+	}
+
+	nb.SetMaskedTokens()
+	if got, _ := nb.Predict("good bad bad"); got != "negative" {
+		t.Errorf("after unmasking Predict = %s, want negative", got)
+	}
+}
+
+func TestSetClassStopWords(t *testing.T) {
+	nb := trainTiny()
+	nb.Train("okay good", "neutral")
+	const text = "bad great"
+	_, before := nb.Predict(text)
+	pBad := nb.WordClassProbabilities("bad")["negative"]
+
+	nb.SetClassStopWords(map[string][]string{" Negative ": {"BAD"}})
+	_, after := nb.Predict(text)
+
+	// Only the negative score loses its P(bad|negative) term, so the ratio of
+	// the other two classes is unchanged and negative gains 1/P(bad|negative)
+	// against both.
+	if !approxEqual(after["positive"]/after["neutral"], before["positive"]/before["neutral"]) {
+		t.Errorf("positive/neutral ratio changed from %v to %v", before["positive"]/before["neutral"], after["positive"]/after["neutral"])
+	}
+	for _, other := range []string{"positive", "neutral"} {
+		gain := (after["negative"] / after[other]) / (before["negative"] / before[other])
+		if !approxEqual(gain, 1/pBad) {
+			t.Errorf("negative/%s ratio grew by %v, want 1/P(bad|negative) = %v", other, gain, 1/pBad)
+		}
+	}
+
+	nb.SetClassStopWords(nil)
+	if _, reset := nb.Predict(text); !approxEqual(reset["negative"], before["negative"]) {
+		t.Errorf("after clearing the stop words P(negative) = %v, want %v", reset["negative"], before["negative"])
+	}
+}
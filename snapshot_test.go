@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"sentimentbayes/sentiment"
+)
+
+// sameCounts reports whether two snapshots hold identical learned counts.
+func sameCounts(a, b sentiment.Snapshot) bool {
+	return a.TotalDocs == b.TotalDocs &&
+		reflect.DeepEqual(a.ClassDocCounts, b.ClassDocCounts) &&
+		reflect.DeepEqual(a.ClassWordCounts, b.ClassWordCounts) &&
+		reflect.DeepEqual(a.ClassTotalWords, b.ClassTotalWords) &&
+		reflect.DeepEqual(a.Vocabulary, b.Vocabulary)
+}
+
+func TestFinetuneAddsToSnapshotCounts(t *testing.T) {
+	dir := t.TempDir()
+	base := sentiment.NewNaiveBayesClassifier()
+	base.TrainBatch(sentiment.DefaultDataset())
+	snapshotPath := filepath.Join(dir, "model.json")
+	if err := writeSnapshot(base, snapshotPath); err != nil {
+		t.Fatal(err)
+	}
+	finetunePath := filepath.Join(dir, "finetune.csv")
+	if err := os.WriteFile(finetunePath, []byte("text,label\nThe latency is superb,positive\nConstant timeouts,negative\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	setFlag(t, continueTraining, true)
+	setFlag(t, finetuneDataset, finetunePath)
+
+	classifier := sentiment.NewNaiveBayesClassifier()
+	loaded, err := loadSnapshotFromDisk(classifier, snapshotPath, nil)
+	if err != nil || !loaded {
+		t.Fatalf("loadSnapshotFromDisk = %v, %v", loaded, err)
+	}
+	trainDocs, err := trainingDocuments(classifier, sentiment.DefaultDataset(), loaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trainClassifier(classifier, trainDocs)
+
+	want := sentiment.NewNaiveBayesClassifier()
+	want.TrainBatch(sentiment.DefaultDataset())
+	want.TrainBatch([]sentiment.Document{
+		{Text: "The latency is superb", Label: "positive"},
+		{Text: "Constant timeouts", Label: "negative"},
+	})
+	if !sameCounts(classifier.Snapshot(), want.Snapshot()) {
+		t.Errorf("fine-tuned counts differ from snapshot + finetune counts: got %v docs, want %v",
+			classifier.TotalDocs(), want.TotalDocs())
+	}
+}
+
+func TestFinetuneRequiresSnapshotAndContinueTraining(t *testing.T) {
+	setFlag(t, finetuneDataset, "finetune.csv")
+	docs := sentiment.DefaultDataset()
+	classifier := sentiment.NewNaiveBayesClassifier()
+	if _, err := trainingDocuments(classifier, docs, false); err == nil {
+		t.Error("-finetune-dataset without a snapshot succeeded")
+	}
+	if _, err := trainingDocuments(classifier, docs, true); err == nil {
+		t.Error("-finetune-dataset without -continue-training succeeded")
+	}
+}
+
+// writeOptionsSnapshot saves a model trained with non-default options and
+// returns it with the snapshot path.
+func writeOptionsSnapshot(t *testing.T) (*sentiment.NaiveBayesClassifier, string) {
+	t.Helper()
+	model := sentiment.NewNaiveBayesClassifier(
+		sentiment.NGrams(2),
+		sentiment.PriorWeight(0.5),
+		sentiment.MaxTokenRepeats(3),
+		sentiment.SentenceBreaks(),
+		sentiment.TokenDenyPattern(regexp.MustCompile(`^\d+$`)),
+	)
+	model.TrainBatch(sentiment.DefaultDataset())
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := writeSnapshot(model, path); err != nil {
+		t.Fatal(err)
+	}
+	return model, path
+}
+
+func TestSnapshotOptionsRoundTrip(t *testing.T) {
+	model, path := writeOptionsSnapshot(t)
+
+	loaded := sentiment.NewNaiveBayesClassifier()
+	if _, err := loadSnapshotFromDisk(loaded, path, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := loaded.Snapshot().Options, model.Snapshot().Options; !reflect.DeepEqual(got, want) {
+		t.Errorf("restored options = %+v, want %+v", got, want)
+	}
+	for _, text := range sentiment.DemoSentences {
+		wantLabel, wantProbs := model.Predict(text)
+		if label, probs := loaded.Predict(text); label != wantLabel || !reflect.DeepEqual(probs, wantProbs) {
+			t.Errorf("Predict(%q) = %s %v after reload, want %s %v", text, label, probs, wantLabel, wantProbs)
+		}
+	}
+}
+
+func TestExplicitFlagsOverrideSnapshotOptions(t *testing.T) {
+	model, path := writeOptionsSnapshot(t)
+	setFlag(t, ngramMax, 1)
+	setFlag(t, priorWeight, 0.5)
+	logs := captureLog(t)
+
+	loaded := sentiment.NewNaiveBayesClassifier()
+	explicit := map[string]bool{"ngrams": true, "prior-weight": true}
+	if _, err := loadSnapshotFromDisk(loaded, path, explicit); err != nil {
+		t.Fatal(err)
+	}
+
+	got := loaded.Snapshot().Options
+	want := *model.Snapshot().Options
+	want.MaxNGram = 1
+	if !reflect.DeepEqual(*got, want) {
+		t.Errorf("options = %+v, want the snapshot's with the explicit -ngrams 1: %+v", *got, want)
+	}
+	if !strings.Contains(logs.String(), "WARNING: -ngrams=1 overrides") {
+		t.Errorf("no warning for the overridden -ngrams in logs:\n%s", logs.String())
+	}
+	// -prior-weight matches the saved value, so nothing is overridden.
+	if strings.Contains(logs.String(), "-prior-weight") {
+		t.Errorf("warning for -prior-weight, which matches the snapshot:\n%s", logs.String())
+	}
+}
+
+func TestLoadSnapshotFromStdin(t *testing.T) {
+	source := trainedClassifier()
+	path := filepath.Join(t.TempDir(), "model.json")
+	if err := writeSnapshot(source, path); err != nil {
+		t.Fatal(err)
+	}
+	stdin, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	setFlag(t, &os.Stdin, stdin)
+
+	classifier := sentiment.NewNaiveBayesClassifier()
+	if loaded, err := loadSnapshotFromDisk(classifier, "-", nil); err != nil || !loaded {
+		t.Fatalf("loadSnapshotFromDisk(-) = %v, %v", loaded, err)
+	}
+	if classifier.Fingerprint() != source.Fingerprint() {
+		t.Error("the snapshot read from stdin differs from the one written")
+	}
+}
+
+func TestLoadSnapshotFromURL(t *testing.T) {
+	source := trainedClassifier()
+	payload, err := json.Marshal(source.Snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/model.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(payload)
+	}))
+	defer srv.Close()
+
+	classifier := sentiment.NewNaiveBayesClassifier()
+	if loaded, err := loadSnapshotFromDisk(classifier, srv.URL+"/models/model.json", nil); err != nil || !loaded {
+		t.Fatalf("loadSnapshotFromDisk(URL) = %v, %v", loaded, err)
+	}
+	if classifier.Fingerprint() != source.Fingerprint() {
+		t.Error("the snapshot fetched over HTTP differs from the one served")
+	}
+
+	_, err = loadSnapshotFromDisk(sentiment.NewNaiveBayesClassifier(), srv.URL+"/missing.json", nil)
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("missing URL: err = %v, want a 404 error", err)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"reflect"
+
+	"sentimentbayes/sentiment"
+)
+
+// snapshotOptionFlags maps each classifier flag to the snapshot option it
+// controls. set writes the flag's value into the options.
+var snapshotOptionFlags = []struct {
+	name string
+	set  func(o *sentiment.SnapshotOptions)
+}{
+	{"prior-weight", func(o *sentiment.SnapshotOptions) { o.PriorWeight = *priorWeight }},
+	{"tf-mode", func(o *sentiment.SnapshotOptions) { o.TermFrequency = sentiment.TermFrequencyMode(*tfMode) }},
+	{"sublinear-tf", func(o *sentiment.SnapshotOptions) {
+		if *sublinearTF {
+			o.TermFrequency = sentiment.TFLog
+		} else {
+			o.TermFrequency = sentiment.TermFrequencyMode(*tfMode)
+		}
+	}},
+	{"position-features", func(o *sentiment.SnapshotOptions) { o.PositionFeatures = *positionFeatures }},
+	{"max-vocab", func(o *sentiment.SnapshotOptions) { o.MaxVocabSize = *maxVocabSize }},
+	{"min-df", func(o *sentiment.SnapshotOptions) { o.MinDocFrequency = *minDocFreq }},
+	{"good-turing-oov", func(o *sentiment.SnapshotOptions) { o.GoodTuringOOV = *goodTuringOOV }},
+	{"ngrams", func(o *sentiment.SnapshotOptions) { o.MaxNGram = *ngramMax }},
+	{"bigram-weight", func(o *sentiment.SnapshotOptions) {
+		o.NGramWeights = nil
+		if *bigramWeight != 1 {
+			o.NGramWeights = map[int]float64{2: *bigramWeight}
+		}
+	}},
+	{"max-token-repeats", func(o *sentiment.SnapshotOptions) { o.MaxTokenRepeats = *maxTokenRepeats }},
+	{"sentence-breaks", func(o *sentiment.SnapshotOptions) { o.SentenceBreaks = *sentenceBreaks }},
+	{"cooc-window", func(o *sentiment.SnapshotOptions) { o.CoOccurrence = *coocWindow }},
+	{"strip-html", func(o *sentiment.SnapshotOptions) { o.StripHTML = *stripHTML }},
+	{"split-identifiers", func(o *sentiment.SnapshotOptions) { o.SplitIdentifiers = *splitIdents }},
+	{"collapse-repeats", func(o *sentiment.SnapshotOptions) { o.CollapseRepeats = *collapseRepeats }},
+	{"hash-buckets", func(o *sentiment.SnapshotOptions) { o.HashBuckets = *hashBuckets }},
+	{"decay", func(o *sentiment.SnapshotOptions) { o.DecayFactor = *decayFactor }},
+	{"token-allow", func(o *sentiment.SnapshotOptions) { o.TokenAllow = *tokenAllow }},
+	{"token-deny", func(o *sentiment.SnapshotOptions) { o.TokenDeny = *tokenDeny }},
+}
+
+// explicitFlags returns the names of the flags set on the command line or by
+// the -config file.
+func explicitFlags(fs *flag.FlagSet) map[string]bool {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	return explicit
+}
+
+// overrideSnapshotOptions replaces the options saved in a snapshot with the
+// values of the classifier flags named in explicit, so an option the user
+// asked for is not silently undone by loading a model. Every option that
+// changes is logged.
+func overrideSnapshotOptions(opts *sentiment.SnapshotOptions, explicit map[string]bool) {
+	for _, f := range snapshotOptionFlags {
+		if !explicit[f.name] {
+			continue
+		}
+		saved := *opts
+		f.set(opts)
+		if !reflect.DeepEqual(saved, *opts) {
+			log.Printf("WARNING: -%s=%s overrides the value saved in the snapshot", f.name, flag.Lookup(f.name).Value)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"sentimentbayes/sentiment"
+)
+
+type streamEvent struct {
+	Line          int            `json:"line"`
+	Label         string         `json:"label"`
+	Probabilities probabilityMap `json:"probabilities"`
+}
+
+// streamHandler classifies a newline-delimited request body as it arrives and
+// answers with one Server-Sent Event per non-empty line, flushed immediately.
+// The connection is exempt from the server read and write timeouts, and the
+// handler stops as soon as the client goes away. The model lock is taken per
+// line so a long-lived stream does not block /train.
+func streamHandler(classifier *sentiment.NaiveBayesClassifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		rc := http.NewResponseController(w)
+		// Not every ResponseWriter supports deadlines; the server-wide timeouts apply then.
+		_ = rc.SetReadDeadline(time.Time{})
+		_ = rc.SetWriteDeadline(time.Time{})
+		// HTTP/1.x closes the request body once the response starts unless
+		// full duplex is enabled; HTTP/2 always allows it.
+		_ = rc.EnableFullDuplex()
+
+		// The status line is sent with the first event rather than up front:
+		// answering before the body is read would skip "Expect: 100-continue"
+		// and make the server discard the request body.
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+
+		scanner := bufio.NewScanner(r.Body)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		line := 0
+		for scanner.Scan() {
+			line++
+			if r.Context().Err() != nil {
+				return
+			}
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			modelLock.RLock()
+			label, probs := predictText(classifier, text)
+			modelLock.RUnlock()
+
+			payload, err := json.Marshal(streamEvent{Line: line, Label: label, Probabilities: roundProbabilities(probs)})
+			if err != nil {
+				return
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", payload); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil && r.Context().Err() == nil {
+			fmt.Fprintf(w, "event: error\ndata: %q\n\n", err.Error())
+			rc.Flush()
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"sentimentbayes/sentiment"
+)
+
+// modelLock guards the classifier in serve mode. /train and /correct hold it
+// for writing while every other endpoint holds it for reading, since Predict
+// must not run concurrently with training.
+var modelLock sync.RWMutex
+
+type trainRequest struct {
+	Text  string `json:"text"`
+	Label string `json:"label"`
+}
+
+type trainResponse struct {
+	Label     string  `json:"label"`
+	Weight    float64 `json:"weight"`
+	TotalDocs float64 `json:"total_docs"`
+	RequestID string  `json:"request_id,omitempty"`
+}
+
+// withModelLock takes the read side of modelLock around every request except
+// /train, /correct and /stream, which lock the model themselves.
+func withModelLock(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/train" || r.URL.Path == "/correct" || r.URL.Path == "/stream" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		modelLock.RLock()
+		defer modelLock.RUnlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// trainHandler adds a labeled example to the live model, counting it weight
+// times so feedback outweighs an ordinary training document.
+//
+// Heavily weighted feedback makes the model drift away from its original
+// training data, and the changes only live in memory. Keep periodic
+// snapshots of the model so a bad batch of corrections can be rolled back.
+func trainHandler(classifier *sentiment.NaiveBayesClassifier, weight float64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req trainRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		text := strings.TrimSpace(req.Text)
+		label := sentiment.NormalizeLabel(req.Label)
+		if text == "" || label == "" {
+			http.Error(w, "text and label are required", http.StatusBadRequest)
+			return
+		}
+
+		modelLock.Lock()
+		classifier.TrainWeighted(text, label, weight)
+		totalDocs := classifier.TotalDocs()
+		modelLock.Unlock()
+
+		resp := trainResponse{Label: label, Weight: weight, TotalDocs: totalDocs, RequestID: requestIDFrom(r.Context())}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+type correctRequest struct {
+	Text         string `json:"text"`
+	CorrectLabel string `json:"correct_label"`
+}
+
+type correctPrediction struct {
+	Label         string         `json:"label"`
+	Probabilities probabilityMap `json:"probabilities"`
+}
+
+type correctResponse struct {
+	CorrectLabel string            `json:"correct_label"`
+	Before       correctPrediction `json:"before"`
+	After        correctPrediction `json:"after"`
+	TotalDocs    float64           `json:"total_docs"`
+	RequestID    string            `json:"request_id,omitempty"`
+}
+
+// correctHandler trains on a corrected example like trainHandler and answers
+// with the model's prediction for the same text before and after, so a
+// reviewer sees the effect of the correction in one round trip. Both
+// predictions and the update happen under the write lock, so no other
+// request changes the model in between.
+//
+// The drift risk of /train applies here as well: every correction moves the
+// live model towards the reviewer's labels and is lost on restart unless a
+// snapshot is saved.
+func correctHandler(classifier *sentiment.NaiveBayesClassifier, weight float64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var req correctRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		text := strings.TrimSpace(req.Text)
+		label := sentiment.NormalizeLabel(req.CorrectLabel)
+		if text == "" || label == "" {
+			http.Error(w, "text and correct_label are required", http.StatusBadRequest)
+			return
+		}
+
+		modelLock.Lock()
+		beforeLabel, beforeProbs := predictText(classifier, text)
+		classifier.TrainWeighted(text, label, weight)
+		afterLabel, afterProbs := predictText(classifier, text)
+		totalDocs := classifier.TotalDocs()
+		modelLock.Unlock()
+
+		resp := correctResponse{
+			CorrectLabel: label,
+			Before:       correctPrediction{Label: beforeLabel, Probabilities: roundProbabilities(beforeProbs)},
+			After:        correctPrediction{Label: afterLabel, Probabilities: roundProbabilities(afterProbs)},
+			TotalDocs:    totalDocs,
+			RequestID:    requestIDFrom(r.Context()),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"sentimentbayes/sentiment"
+)
+
+func TestCorrectHandler(t *testing.T) {
+	classifier := sentiment.NewNaiveBayesClassifier()
+	classifier.Train("good good great", "positive")
+	classifier.Train("bad good", "negative")
+	setFlag(t, feedbackWeight, 3.0)
+	router := buildRouter(classifier, nil, nil)
+	before := classifier.Fingerprint()
+
+	var resp correctResponse
+	decodeBody(t, serve(t, router, http.MethodPost, "/correct", correctRequest{Text: "great", CorrectLabel: " Negative "}), &resp)
+	if resp.CorrectLabel != "negative" || resp.TotalDocs != 5 {
+		t.Errorf("correct_label %q, total_docs %v; want negative and 5", resp.CorrectLabel, resp.TotalDocs)
+	}
+	if resp.Before.Label != "positive" || resp.After.Label != "negative" {
+		t.Errorf("prediction went from %s to %s, want positive to negative", resp.Before.Label, resp.After.Label)
+	}
+	if resp.After.Probabilities["negative"] <= resp.Before.Probabilities["negative"] {
+		t.Errorf("P(negative) went from %v to %v, want it to rise", resp.Before.Probabilities["negative"], resp.After.Probabilities["negative"])
+	}
+	if classifier.Fingerprint() == before {
+		t.Error("the correction did not change the model")
+	}
+	if label, _ := classifier.Predict("great"); label != "negative" {
+		t.Errorf("live model predicts %s for the corrected text, want negative", label)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		body   any
+		want   int
+	}{
+		{"missing label", http.MethodPost, correctRequest{Text: "great"}, http.StatusBadRequest},
+		{"blank text", http.MethodPost, correctRequest{Text: "  ", CorrectLabel: "positive"}, http.StatusBadRequest},
+		{"invalid JSON", http.MethodPost, "not an object", http.StatusBadRequest},
+		{"GET", http.MethodGet, nil, http.StatusMethodNotAllowed},
+	}
+	after := classifier.Fingerprint()
+	for _, tt := range tests {
+		if rec := serve(t, router, tt.method, "/correct", tt.body); rec.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, rec.Code, tt.want)
+		}
+	}
+	if classifier.Fingerprint() != after {
+		t.Error("a rejected correction changed the model")
+	}
+}
+
+func TestCorrectDisabledWithoutFeedbackWeight(t *testing.T) {
+	router := buildRouter(trainedClassifier(), nil, nil)
+	rec := serve(t, router, http.MethodPost, "/correct", correctRequest{Text: "great", CorrectLabel: "negative"})
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "not found") {
+		t.Errorf("status = %d %q, want 404 without -feedback-weight", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"strconv"
+
+	"sentimentbayes/sentiment"
+)
+
+// runVocabMode writes the sorted vocabulary, one token per line, to
+// outputPath (stdout when empty). With counts, each line is "token<TAB>count".
+func runVocabMode(classifier *sentiment.NaiveBayesClassifier, docs []sentiment.Document, outputPath string, counts, train bool) error {
+	if train {
+		trainClassifier(classifier, docs)
+	}
+	logModelSummary(classifier)
+	if err := saveSnapshotIfNeeded(classifier); err != nil {
+		return err
+	}
+
+	out, err := openPredictionOutput(outputPath)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(out)
+	entries := classifier.Vocabulary()
+	for _, entry := range entries {
+		if counts {
+			fmt.Fprintf(w, "%s\t%s\n", entry.Token, strconv.FormatFloat(entry.Count, 'f', -1, 64))
+		} else {
+			fmt.Fprintln(w, entry.Token)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		out.Close()
+		return fmt.Errorf("write vocabulary: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("close output: %w", err)
+	}
+	if outputPath != "" {
+		log.Printf("Wrote %d vocabulary tokens to %s", len(entries), outputPath)
+	}
+	return nil
+}
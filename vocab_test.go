@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sentimentbayes/sentiment"
+)
+
+func TestRunVocabModeWritesSortedCounts(t *testing.T) {
+	classifier := sentiment.NewNaiveBayesClassifier()
+	docs := []sentiment.Document{
+		{Text: "zesty apple apple", Label: "positive"},
+		{Text: "mushy apple", Label: "negative"},
+	}
+	path := filepath.Join(t.TempDir(), "vocab.tsv")
+	if err := runVocabMode(classifier, docs, path, true, true); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "apple\t3\nmushy\t1\nzesty\t1\n"
+	if string(got) != want {
+		t.Errorf("vocabulary file = %q, want %q", got, want)
+	}
+
+	if err := runVocabMode(classifier, nil, path, false, false); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "apple\nmushy\nzesty\n" {
+		t.Errorf("vocabulary file without counts = %q", got)
+	}
+}